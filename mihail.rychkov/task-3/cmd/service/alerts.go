@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/Rychmick/task-3/internal/alert"
+	"github.com/Rychmick/task-3/internal/archive"
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/output"
+)
+
+var errAlertsTriggered = errors.New("alert rules fired")
+
+// evaluateAlerts checks the rules against the rates as published, before any
+// rebase or selection. Relative rules compare with the latest archived snapshot
+// older than this one and are skipped when there is none.
+func evaluateAlerts(settings config.Settings, rates *currency.Rates) ([]alert.Alert, error) {
+	rules := settings.Alerts.Rules
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var previous *currency.Rates
+
+	if slices.ContainsFunc(rules, func(rule alert.Rule) bool { return rule.Relative() }) {
+		snapshot, err := previousSnapshot(settings, rates)
+
+		switch {
+		case err == nil:
+			previous = &snapshot
+		case errors.Is(err, archive.ErrNotFound) || errors.Is(err, currency.ErrNoDate):
+			log.Printf("skipping alert rules on rate changes: %v", err)
+
+			rules = slices.DeleteFunc(slices.Clone(rules), func(rule alert.Rule) bool { return rule.Relative() })
+		default:
+			return nil, err
+		}
+	}
+
+	alerts, err := alert.Evaluate(rules, rates, previous)
+	if err != nil {
+		return nil, fmt.Errorf("cannot evaluate alert rules: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func previousSnapshot(settings config.Settings, rates *currency.Rates) (currency.Rates, error) {
+	day, err := rates.Day()
+	if err != nil {
+		return currency.Rates{}, err //nolint:wrapcheck
+	}
+
+	store := archive.Archive{Dir: settings.ArchiveDir}
+
+	return store.Before(day) //nolint:wrapcheck
+}
+
+// raiseAlerts runs the configured actions. The alerts file is rewritten on every
+// run, so it never shows alerts of an earlier snapshot.
+func raiseAlerts(settings config.Settings, date string, alerts []alert.Alert) error {
+	actions := settings.Alerts
+	if len(actions.Rules) == 0 {
+		return nil
+	}
+
+	if actions.File != "" {
+		err := output.WriteFile(actions.File, "", alert.Columns(), alert.Records(alerts), settings.FileOptions())
+		if err != nil {
+			return fmt.Errorf("cannot write alerts file: %w", err)
+		}
+	}
+
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	if actions.Stderr {
+		for idx := range alerts {
+			log.Printf("alert: %s", alerts[idx].Message)
+		}
+	}
+
+	if actions.Webhook != "" {
+		hook := alert.Webhook{URL: actions.Webhook, Timeout: actions.WebhookTimeout, HTTPClient: nil}
+
+		err := hook.Send(context.Background(), date, alerts)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	if actions.ExitCode {
+		return fmt.Errorf("%w: %d of %d", errAlertsTriggered, len(alerts), len(actions.Rules))
+	}
+
+	return nil
+}
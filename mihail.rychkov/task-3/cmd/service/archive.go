@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/archive"
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/output"
+)
+
+const archiveUsage = "archive ingest FILE... | archive rate CODE DATE | archive series CODE [FROM [TO]]"
+
+func parseDay(raw string) (time.Time, error) {
+	day, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return day, fmt.Errorf("%w: bad date %q, expected YYYY-MM-DD", errUsage, raw)
+	}
+
+	return day, nil
+}
+
+func ingestFiles(store *archive.Archive, settings config.Settings, paths []string, stdout io.Writer) error {
+	for _, path := range paths {
+		rates, err := decodeFile(settings, path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		day, err := store.Ingest(rates)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		fmt.Fprintf(stdout, "%s -> %s\n", path, day.Format(time.DateOnly))
+	}
+
+	return nil
+}
+
+func writeObservations(settings config.Settings, observations []archive.Observation, stdout io.Writer) error {
+	writer, err := output.Resolve(settings.OutputFormat, "")
+	if err != nil {
+		return err
+	}
+
+	records := make([]output.Record, len(observations))
+	for idx := range observations {
+		records[idx] = observations[idx].Record()
+	}
+
+	return writer.Write(stdout, records)
+}
+
+func runArchive(settings config.Settings, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: %s", errUsage, archiveUsage)
+	}
+
+	store := archive.Archive{Dir: settings.ArchiveDir}
+
+	switch {
+	case args[0] == "ingest" && len(args) > 1:
+		return ingestFiles(&store, settings, args[1:], stdout)
+	case args[0] == "rate" && len(args) == 3: //nolint:mnd
+		day, err := parseDay(args[2])
+		if err != nil {
+			return err
+		}
+
+		observation, err := store.AsOf(args[1], day)
+		if err != nil {
+			return err
+		}
+
+		return writeObservations(settings, []archive.Observation{observation}, stdout)
+	case args[0] == "series" && len(args) >= 2 && len(args) <= 4: //nolint:mnd
+		var bounds [2]time.Time
+
+		for idx, raw := range args[2:] {
+			day, err := parseDay(raw)
+			if err != nil {
+				return err
+			}
+
+			bounds[idx] = day
+		}
+
+		observations, err := store.Series(args[1], bounds[0], bounds[1])
+		if err != nil {
+			return err
+		}
+
+		return writeObservations(settings, observations, stdout)
+	}
+
+	return fmt.Errorf("%w: %s", errUsage, archiveUsage)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+
+	"github.com/Rychmick/task-3/internal/batch"
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	batchUsage    = "batch [INPUT-DIR|GLOB [OUTPUT-DIR]]"
+	batchMaxArgs  = 2
+	manifestName  = "manifest.json"
+	defaultOutDir = "output"
+)
+
+var errBatchFailed = errors.New("some files failed to convert")
+
+func batchSettings(settings config.Settings, args []string) (config.BatchSettings, error) {
+	result := settings.Batch
+
+	if len(args) > batchMaxArgs {
+		return result, fmt.Errorf("%w: %s", errUsage, batchUsage)
+	}
+
+	if len(args) > 0 {
+		result.Input = args[0]
+	}
+
+	if len(args) > 1 {
+		result.OutputDir = args[1]
+	}
+
+	if result.Input == "" {
+		return result, fmt.Errorf("%w: batch input is not set: %s", errUsage, batchUsage)
+	}
+
+	if result.OutputDir == "" {
+		result.OutputDir = defaultOutDir
+	}
+
+	if result.Manifest == "" {
+		result.Manifest = filepath.Join(result.OutputDir, manifestName)
+	}
+
+	return result, nil
+}
+
+// convertOne runs the regular export for a single batch job on its own copy of
+// the settings, as jobs run concurrently. Problem reports are left out, as
+// concurrent jobs would overwrite each other's, and so are alerts, which are
+// meant for the latest snapshot rather than every file of a batch.
+func convertOne(settings config.Settings) batch.ConvertFunc {
+	return func(_ context.Context, job batch.Job) (int, error) {
+		current := settings
+
+		current.InputFilePath = job.Input
+		current.OutputFilePath = job.Output
+		current.Fetch.Enabled = false
+		current.Parsing.Report = ""
+		current.Alerts.Rules = nil
+
+		return runExport(current)
+	}
+}
+
+func runBatch(settings config.Settings, args []string, stdout io.Writer) error {
+	opts, err := batchSettings(settings, args)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := batch.Expand(opts.Input)
+	if err != nil {
+		return err
+	}
+
+	format := settings.OutputFormat
+	if format == "" {
+		format = output.FormatFromPath(settings.OutputFilePath)
+	}
+
+	// the output format is fixed by the extension from here on
+	settings.OutputFormat = format
+
+	fingerprint, err := yaml.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("cannot fingerprint settings: %w", err)
+	}
+
+	previous, err := batch.LoadManifest(opts.Manifest)
+	if err != nil {
+		log.Printf("ignoring previous manifest: %v", err)
+	}
+
+	runner := batch.Runner{Workers: opts.Workers, Fingerprint: fingerprint, Previous: previous}
+	manifest := runner.Run(context.Background(), batch.Jobs(inputs, opts.OutputDir, output.Extension(format)),
+		convertOne(settings))
+
+	for _, entry := range manifest.Entries {
+		if entry.Status == batch.StatusFailed {
+			log.Printf("%s: %s", entry.Input, entry.Error)
+		}
+	}
+
+	data, err := manifest.Marshal()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	file, err := output.CreateFile(opts.Manifest, settings.FileOptions())
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	_, err = file.Write(data)
+	if err != nil {
+		file.Abort()
+
+		return fmt.Errorf("cannot write manifest: %w", err)
+	}
+
+	err = file.Commit()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	fmt.Fprintf(stdout, "%d files: %d converted, %d unchanged, %d failed in %.2fs\n", manifest.Files,
+		manifest.Converted, manifest.Unchanged, manifest.Failed, manifest.Seconds)
+
+	if manifest.Failed > 0 {
+		return fmt.Errorf("%w: %d of %d, see %s", errBatchFailed, manifest.Failed, manifest.Files, opts.Manifest)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/batch"
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const batchInput = `<?xml version="1.0" encoding="UTF-8"?>
+<ValCurs Date="%02d.03.2024" name="Foreign Currency Market">
+  <Valute ID="R01235">
+    <NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal>
+    <Name>Доллар США</Name><Value>9%d,5</Value>
+  </Valute>
+</ValCurs>`
+
+func TestRunBatchWorkers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputDir := filepath.Join(dir, "input")
+	outputDir := filepath.Join(dir, "output")
+
+	require.NoError(t, os.Mkdir(inputDir, 0o700))
+
+	const files = 32
+
+	for idx := range files {
+		content := fmt.Sprintf(batchInput, idx+1, idx)
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, fmt.Sprintf("rates-%d.xml", idx)), []byte(content), 0o600))
+	}
+
+	alertsFile := filepath.Join(dir, "alerts.csv")
+
+	settings, err := config.Parse("", "output-file=rates.json", "batch.workers=4",
+		"alerts.rules=USD > 1", "alerts.file="+alertsFile, "alerts.exit-code=true")
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+
+	require.NoError(t, runBatch(settings, []string{inputDir, outputDir}, &stdout))
+	assert.Contains(t, stdout.String(), fmt.Sprintf("%d files: %d converted", files, files))
+
+	for idx := range files {
+		content, err := os.ReadFile(filepath.Join(outputDir, fmt.Sprintf("rates-%d.json", idx)))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), fmt.Sprintf("9%d.5", idx))
+	}
+
+	manifest, err := batch.LoadManifest(filepath.Join(outputDir, manifestName))
+	require.NoError(t, err)
+	assert.Equal(t, files, manifest.Converted)
+	assert.NoFileExists(t, alertsFile)
+}
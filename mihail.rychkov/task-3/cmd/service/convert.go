@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/converter"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+)
+
+const (
+	defaultPlaces = 4
+	convertUsage  = "convert AMOUNT FROM TO"
+	convertArgs   = 3
+)
+
+func places(settings config.Settings) int32 {
+	if settings.Precision != nil {
+		return *settings.Precision
+	}
+
+	return defaultPlaces
+}
+
+func rebase(rates currency.Rates, settings config.Settings) (currency.Rates, error) {
+	conv, err := converter.New(rates)
+	if err != nil {
+		return rates, err
+	}
+
+	rebased, err := conv.Rebase(settings.BaseCurrency)
+	if err != nil {
+		return rates, fmt.Errorf("cannot rebase rates: %w", err)
+	}
+
+	rebased.Date = rates.Date
+	rebased.Name = rates.Name
+
+	return rebased, nil
+}
+
+func runConvert(settings config.Settings, args []string, stdout io.Writer) error {
+	if len(args) != convertArgs {
+		return fmt.Errorf("%w: %s", errUsage, convertUsage)
+	}
+
+	amount, err := decimal.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	var rates currency.Rates
+
+	err = loadRates(settings, &rates)
+	if err != nil {
+		return err
+	}
+
+	conv, err := converter.New(rates)
+	if err != nil {
+		return err
+	}
+
+	from, to := strings.ToUpper(args[1]), strings.ToUpper(args[2])
+
+	result, err := conv.Convert(amount, from, to, places(settings))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "%s %s = %s %s\n", amount, from, result, to)
+	if err != nil {
+		return fmt.Errorf("cannot write result: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/diff"
+	"github.com/Rychmick/task-3/internal/output"
+)
+
+const (
+	diffUsage = "diff OLD NEW"
+	diffArgs  = 2
+)
+
+func runDiff(settings config.Settings, args []string, stdout io.Writer) error {
+	if len(args) != diffArgs {
+		return fmt.Errorf("%w: %s", errUsage, diffUsage)
+	}
+
+	before, err := decodeFile(settings, args[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	after, err := decodeFile(settings, args[1])
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[1], err)
+	}
+
+	changes, err := diff.Compare(before, after, places(settings))
+	if err != nil {
+		return err
+	}
+
+	writer, err := output.Resolve(settings.OutputFormat, "")
+	if err != nil {
+		return err
+	}
+
+	return output.WriteTable(writer, stdout, diff.Columns(), diff.Records(changes)) //nolint:wrapcheck
+}
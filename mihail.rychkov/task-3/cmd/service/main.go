@@ -1,45 +1,281 @@
 package main
 
 import (
-	"cmp"
+	"bytes"
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"log"
 	"os"
-	"slices"
+	"time"
 
+	"github.com/Rychmick/task-3/internal/cbr"
+	"github.com/Rychmick/task-3/internal/compress"
 	"github.com/Rychmick/task-3/internal/config"
 	"github.com/Rychmick/task-3/internal/currency"
-	"github.com/Rychmick/task-3/internal/json"
-	"github.com/Rychmick/task-3/internal/xml"
+	"github.com/Rychmick/task-3/internal/output"
 )
 
-const defaultFileMode = os.FileMode(0o666)
+const (
+	exitFailure      = 1
+	exitUsage        = 2
+	exitInvalidInput = 3
+	exitAlert        = 4
+)
+
+var (
+	errUnknownCommand = errors.New("unknown command")
+	errUsage          = errors.New("invalid arguments")
+)
+
+func fetchRates(settings config.FetchSettings) ([]byte, error) {
+	var date time.Time
+
+	if settings.Date != "" {
+		parsed, err := time.Parse(time.DateOnly, settings.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cbr date: %w", err)
+		}
+
+		date = parsed
+	}
+
+	client := cbr.Client{
+		BaseURL:    settings.BaseURL,
+		Timeout:    settings.Timeout,
+		Retries:    settings.Retries,
+		RetryDelay: settings.RetryDelay,
+		CacheDir:   settings.CacheDir,
+		HTTPClient: nil,
+	}
+
+	snapshot, err := client.Fetch(context.Background(), date)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch rates: %w", err)
+	}
+
+	if snapshot.Origin == cbr.OriginOffline {
+		log.Println("cbr service is unreachable, using the last cached copy")
+	}
 
-func compareValues(lhs, rhs currency.Currency) int {
-	return -cmp.Compare(lhs.Value, rhs.Value)
+	return snapshot.Body, nil
 }
 
-func main() {
-	var configPath string
+// openInput returns the input and its name for format detection: fetched
+// documents have no name, compressed files are named after their content.
+func openInput(settings config.Settings) (io.ReadCloser, string, error) {
+	if settings.Fetch.Enabled {
+		body, err := fetchRates(settings.Fetch)
+		if err != nil {
+			return nil, "", err
+		}
 
-	flag.StringVar(&configPath, "config", "config.yaml", "path to config file")
-	flag.Parse()
+		return io.NopCloser(bytes.NewReader(body)), "", nil
+	}
 
-	settings, err := config.Parse(configPath)
+	file, name, err := compress.Open(settings.InputFilePath)
 	if err != nil {
-		panic(err)
+		return nil, "", fmt.Errorf("cannot read currency list file: %w", err)
 	}
 
+	return file, name, nil
+}
+
+// inputFile is the file behind the input setting, the archive for zip entries.
+func inputFile(settings config.Settings) string {
+	file, _ := compress.Split(settings.InputFilePath)
+
+	return file
+}
+
+func loadRates(settings config.Settings, result *currency.Rates) error {
+	input, name, err := openInput(settings)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	*result, err = decodeRates(settings, input, name)
+
+	return err
+}
+
+// export writes the converted table and reports how many currencies it holds.
+func export(settings config.Settings) (int, error) {
 	var currencyList currency.Rates
 
-	err = xml.ParseFile(settings.InputFilePath, &currencyList)
+	err := loadRates(settings, &currencyList)
+	if err != nil && !errors.Is(err, errTooManyProblems) {
+		return 0, err
+	}
+
+	problems := err
+
+	alerts, err := evaluateAlerts(settings, &currencyList)
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 
-	slices.SortStableFunc(currencyList.Data, compareValues)
+	date := currencyList.Date
+
+	if settings.BaseCurrency != "" {
+		currencyList, err = rebase(currencyList, settings)
+		if err != nil {
+			return 0, err
+		}
+	}
 
-	err = json.WriteFile(settings.OutputFilePath, currencyList.Data, defaultFileMode)
+	currencyList.Data, err = settings.Selection.Apply(currencyList.Data)
 	if err != nil {
-		panic(err)
+		return 0, fmt.Errorf("cannot select rates: %w", err)
+	}
+
+	if settings.Precision != nil {
+		currencyList.Round(*settings.Precision)
+	}
+
+	records, err := settings.Selection.Project(currencyList.Records())
+	if err != nil {
+		return 0, fmt.Errorf("cannot select fields: %w", err)
+	}
+
+	columns := settings.Selection.Columns(currency.Columns(settings.ISO.Enrich))
+
+	err = output.WriteFile(settings.OutputFilePath, settings.OutputFormat, columns, records, settings.FileOptions())
+	if err != nil {
+		return 0, err
+	}
+
+	return len(records), errors.Join(problems, raiseAlerts(settings, date, alerts))
+}
+
+func runExport(settings config.Settings) (int, error) {
+	if settings.Streaming {
+		return exportStream(settings)
+	}
+
+	return export(settings)
+}
+
+const defaultConfigPath = "config.yaml"
+
+// parseFlags returns the config path and the overrides in command line order.
+// Without -config, a missing config.yaml is fine: environment and flags may be enough.
+func parseFlags() (string, []string, bool) {
+	var (
+		configPath string
+		overrides  []string
+		watchMode  bool
+	)
+
+	override := func(key string) func(string) error {
+		return func(value string) error {
+			overrides = append(overrides, key+"="+value)
+
+			return nil
+		}
+	}
+
+	flag.StringVar(&configPath, "config", defaultConfigPath, "path to a yaml, json or toml config file")
+	flag.BoolVar(&watchMode, "watch", false, "keep running and regenerate output whenever the input file changes")
+	flag.Func("input", "input file, overrides input-file", override("input-file"))
+	flag.Func("output", "output file, overrides output-file", override("output-file"))
+	flag.Func("format", "output format, overrides output-format", override("output-format"))
+	flag.Func("set", "override any setting as key=value, e.g. cbr.timeout=5s (repeatable)", func(value string) error {
+		overrides = append(overrides, value)
+
+		return nil
+	})
+	flag.Parse()
+
+	explicit := false
+
+	flag.Visit(func(item *flag.Flag) { explicit = explicit || item.Name == "config" })
+
+	if _, err := os.Stat(configPath); !explicit && errors.Is(err, os.ErrNotExist) {
+		log.Printf("no %s found, using environment and flags only", configPath)
+
+		configPath = ""
+	}
+
+	return configPath, overrides, watchMode
+}
+
+func main() {
+	configPath, overrides, watchMode := parseFlags()
+
+	settings, err := config.Parse(configPath, overrides...)
+	if err != nil {
+		log.Println(err)
+		os.Exit(exitCode(err))
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"export"}
+	}
+
+	err = dispatch(settings, args, watchMode)
+	if err != nil {
+		log.Println(err)
+		os.Exit(exitCode(err))
+	}
+}
+
+func dispatch(settings config.Settings, args []string, watchMode bool) error {
+	switch args[0] {
+	case "export":
+		err := settings.Require(true, true)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if watchMode {
+			return runWatch(settings)
+		}
+
+		_, err = runExport(settings)
+
+		return err
+	case "convert":
+		err := settings.Require(true, false)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return runConvert(settings, args[1:], os.Stdout)
+	case "serve":
+		err := settings.Require(true, false)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		return runServe(settings)
+	case "archive":
+		return runArchive(settings, args[1:], os.Stdout)
+	case "diff":
+		return runDiff(settings, args[1:], os.Stdout)
+	case "batch":
+		return runBatch(settings, args[1:], os.Stdout)
+	default:
+		return fmt.Errorf("%w: %q", errUnknownCommand, args[0])
+	}
+}
+
+func exitCode(err error) int {
+	var parseErr *currency.ParseError
+
+	switch {
+	case errors.Is(err, errAlertsTriggered):
+		return exitAlert
+	case errors.Is(err, errTooManyProblems) || errors.As(err, &parseErr):
+		return exitInvalidInput
+	case errors.Is(err, config.ErrInvalidConfig) || errors.Is(err, config.ErrBadOverride) ||
+		errors.Is(err, errUsage) || errors.Is(err, errUnknownCommand):
+		return exitUsage
+	default:
+		return exitFailure
 	}
 }
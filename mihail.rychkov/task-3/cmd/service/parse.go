@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/Rychmick/task-3/internal/compress"
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/Rychmick/task-3/internal/source"
+)
+
+var errTooManyProblems = errors.New("too many invalid currency entries")
+
+// writeReport saves the problems to the configured report, also when parsing
+// failed on the first of them in strict mode.
+func writeReport(settings config.ParseSettings, opts output.FileOptions, problems []currency.Problem) error {
+	if settings.Report == "" || len(problems) == 0 {
+		return nil
+	}
+
+	records := make([]output.Record, 0, len(problems))
+	for idx := range problems {
+		records = append(records, problems[idx].Record())
+	}
+
+	err := output.WriteFile(settings.Report, "", currency.ProblemColumns(), records, opts)
+	if err != nil {
+		return fmt.Errorf("cannot write parse report: %w", err)
+	}
+
+	return nil
+}
+
+// reportProblems logs every skipped or defaulted entry, saves them to the
+// configured report and fails only once the tolerated amount is exceeded.
+// Even then the rates are usable: exporters still write them and the error
+// only sets the exit code.
+func reportProblems(settings config.ParseSettings, opts output.FileOptions, problems []currency.Problem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	for idx := range problems {
+		log.Println(problems[idx].String())
+	}
+
+	err := writeReport(settings, opts, problems)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > settings.Threshold {
+		return fmt.Errorf("%w: %d found, %d tolerated", errTooManyProblems, len(problems), settings.Threshold)
+	}
+
+	return nil
+}
+
+// decodeRates parses the input in any registered source format; path only helps detection.
+func decodeRates(settings config.Settings, input io.Reader, path string) (currency.Rates, error) {
+	collector := settings.Collector()
+
+	rates, err := source.Decode(input, settings.InputFormat, path, collector)
+	if err != nil {
+		return rates, errors.Join(fmt.Errorf("cannot parse currency list: %w", err),
+			writeReport(settings.Parsing, settings.FileOptions(), collector.Problems()))
+	}
+
+	return rates, reportProblems(settings.Parsing, settings.FileOptions(), collector.Problems())
+}
+
+func decodeFile(settings config.Settings, path string) (currency.Rates, error) {
+	file, name, err := compress.Open(path)
+	if err != nil {
+		return currency.Rates{}, fmt.Errorf("cannot read currency list file: %w", err)
+	}
+	defer file.Close()
+
+	return decodeRates(settings, file, name)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lenientInput = `<?xml version="1.0" encoding="UTF-8"?>
+<ValCurs Date="02.03.2024" name="Foreign Currency Market">
+  <Valute ID="R01235">
+    <NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal>
+    <Name>Доллар США</Name><Value>91,5</Value>
+  </Valute>
+  <Valute ID="R01239">
+    <NumCode>978</NumCode><CharCode>EUR</CharCode><Nominal>1</Nominal>
+    <Name>Евро</Name><Value>n/a</Value>
+  </Valute>
+</ValCurs>`
+
+func TestSkipModeWritesOutputAboveThreshold(t *testing.T) {
+	t.Parallel()
+
+	for _, streaming := range []string{"false", "true"} {
+		t.Run("streaming="+streaming, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			inputPath := filepath.Join(dir, "rates.xml")
+			outputPath := filepath.Join(dir, "rates.json")
+
+			require.NoError(t, os.WriteFile(inputPath, []byte(lenientInput), 0o600))
+
+			settings, err := config.Parse("", "input-file="+inputPath, "output-file="+outputPath,
+				"parsing.mode=skip", "streaming="+streaming)
+			require.NoError(t, err)
+
+			count, err := runExport(settings)
+			require.ErrorIs(t, err, errTooManyProblems)
+			assert.Equal(t, exitInvalidInput, exitCode(err))
+			assert.Equal(t, 1, count)
+
+			content, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "USD")
+			assert.NotContains(t, string(content), "EUR")
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/server"
+	"github.com/Rychmick/task-3/internal/watch"
+)
+
+const (
+	readHeaderTimeout = time.Second * 10
+	shutdownTimeout   = time.Second * 5
+)
+
+func reloader(service *server.Server) func(context.Context) {
+	return func(context.Context) {
+		err := service.Reload()
+		if err != nil {
+			log.Printf("reload failed, serving previous rates: %v", err)
+
+			return
+		}
+
+		log.Println("rates reloaded")
+	}
+}
+
+// refresh reloads fetched rates periodically; there is no file to watch.
+func refresh(ctx context.Context, interval time.Duration, reload func(context.Context)) {
+	reload(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reload(ctx)
+		}
+	}
+}
+
+func runServe(settings config.Settings) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	service := server.New(func() (currency.Rates, error) {
+		var result currency.Rates
+
+		err := loadRates(settings, &result)
+
+		return result, err
+	}, server.Options{
+		Selection:    settings.Selection,
+		Precision:    settings.Precision,
+		BaseCurrency: settings.BaseCurrency,
+	})
+
+	if settings.Fetch.Enabled {
+		go refresh(ctx, settings.Server.Refresh, reloader(service))
+	} else {
+		watcher := watch.Watcher{
+			Path:     inputFile(settings),
+			Interval: settings.Watch.Interval,
+			Debounce: settings.Watch.Debounce,
+			Poll:     settings.Watch.Poll,
+		}
+
+		go func() {
+			err := watcher.Run(ctx, reloader(service))
+			if err != nil {
+				log.Printf("stopped watching %s: %v", settings.InputFilePath, err)
+			}
+		}()
+	}
+
+	httpServer := &http.Server{ //nolint:exhaustruct
+		Addr:              settings.Server.Listen,
+		Handler:           service,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("serving rates on %s", settings.Server.Listen)
+
+	err := httpServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("cannot serve rates: %w", err)
+	}
+
+	return nil
+}
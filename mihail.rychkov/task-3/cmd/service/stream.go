@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/compress"
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/Rychmick/task-3/internal/source"
+)
+
+var (
+	errStreamingOrder  = errors.New("streaming mode cannot sort, truncate or rebase rates")
+	errStreamingSource = errors.New("streaming mode only reads cbr documents")
+)
+
+// streamSource makes sure the input is a CBR document, the only format read element by element.
+func streamSource(settings config.Settings, input io.Reader, name string) (io.Reader, error) {
+	if settings.InputFormat != "" && !strings.EqualFold(settings.InputFormat, source.AutoFormat) {
+		if !strings.EqualFold(settings.InputFormat, "cbr") {
+			return nil, fmt.Errorf("%w, got %s", errStreamingSource, settings.InputFormat)
+		}
+
+		return input, nil
+	}
+
+	reader := bufio.NewReader(input)
+
+	format, err := source.Detect(reader, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errStreamingSource, err)
+	}
+
+	if format != "cbr" {
+		return nil, fmt.Errorf("%w, got %s", errStreamingSource, format)
+	}
+
+	return reader, nil
+}
+
+func streamRecords(settings config.Settings, input io.Reader, stream output.RecordStream) (int, error) {
+	var count int
+
+	decoder := currency.NewDecoder(input, settings.Collector())
+
+	for {
+		var item currency.Currency
+
+		err := decoder.Next(&item)
+		if errors.Is(err, io.EOF) {
+			return count, reportProblems(settings.Parsing, settings.FileOptions(), decoder.Problems())
+		}
+
+		if err != nil {
+			return count, errors.Join(fmt.Errorf("cannot parse currency list: %w", err),
+				writeReport(settings.Parsing, settings.FileOptions(), decoder.Problems()))
+		}
+
+		if !settings.Selection.Keep(&item) {
+			continue
+		}
+
+		if settings.Precision != nil {
+			item.Round(*settings.Precision)
+		}
+
+		records, err := settings.Selection.Project([]output.Record{item.Record()})
+		if err != nil {
+			return count, fmt.Errorf("cannot select fields: %w", err)
+		}
+
+		err = stream.Write(records[0])
+		if err != nil {
+			return count, err
+		}
+
+		count++
+	}
+}
+
+func exportStream(settings config.Settings) (int, error) {
+	if settings.Selection.Ordered() || settings.BaseCurrency != "" {
+		return 0, errStreamingOrder
+	}
+
+	input, name, err := openInput(settings)
+	if err != nil {
+		return 0, err
+	}
+	defer input.Close()
+
+	reader, err := streamSource(settings, input, name)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := output.CreateFile(settings.OutputFilePath, settings.FileOptions())
+	if err != nil {
+		return 0, err
+	}
+
+	buffered := bufio.NewWriter(file)
+
+	compressor, err := compress.NewWriter(settings.OutputFilePath, buffered)
+	if err != nil {
+		file.Abort()
+
+		return 0, err //nolint:wrapcheck
+	}
+
+	stream, err := output.OpenStream(settings.OutputFormat, settings.OutputFilePath, compressor)
+	if err != nil {
+		file.Abort()
+
+		return 0, err
+	}
+
+	count, err := streamRecords(settings, reader, stream)
+
+	var problems error
+	if errors.Is(err, errTooManyProblems) {
+		problems, err = err, nil
+	}
+
+	if err == nil {
+		err = output.WriteHeader(stream, settings.Selection.Columns(currency.Columns(settings.ISO.Enrich)))
+	}
+
+	if err == nil {
+		err = stream.Close()
+	}
+
+	if err == nil {
+		if closeErr := compressor.Close(); closeErr != nil {
+			err = fmt.Errorf("cannot write output file: %w", closeErr)
+		}
+	}
+
+	if err == nil {
+		if flushErr := buffered.Flush(); flushErr != nil {
+			err = fmt.Errorf("cannot write output file: %w", flushErr)
+		}
+	}
+
+	if err != nil {
+		file.Abort()
+
+		return 0, err
+	}
+
+	err = file.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, problems
+}
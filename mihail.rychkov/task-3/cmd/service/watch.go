@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/watch"
+)
+
+var errWatchFetch = errors.New("watch mode needs an input file, not the cbr fetcher")
+
+// runWatch regenerates the output on every input change until interrupted.
+// A failed cycle is only logged: output is written atomically, so the last
+// good file stays in place.
+func runWatch(settings config.Settings) error {
+	if settings.Fetch.Enabled {
+		return errWatchFetch
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watcher := watch.Watcher{
+		Path:     inputFile(settings),
+		Interval: settings.Watch.Interval,
+		Debounce: settings.Watch.Debounce,
+		Poll:     settings.Watch.Poll,
+	}
+
+	var cycle int
+
+	log.Printf("watching %s", inputFile(settings))
+
+	return watcher.Run(ctx, func(context.Context) { //nolint:wrapcheck
+		cycle++
+		started := time.Now()
+
+		count, err := runExport(settings)
+		if errors.Is(err, errAlertsTriggered) || errors.Is(err, errTooManyProblems) {
+			log.Printf("cycle %d: wrote %d currencies to %s, %v", cycle, count, settings.OutputFilePath, err)
+
+			return
+		}
+
+		if err != nil {
+			log.Printf("cycle %d failed, keeping previous output: %v", cycle, err)
+
+			return
+		}
+
+		log.Printf("cycle %d: wrote %d currencies to %s in %s", cycle, count, settings.OutputFilePath,
+			time.Since(started).Round(time.Millisecond))
+	})
+}
@@ -3,8 +3,17 @@ module github.com/Rychmick/task-3
 go 1.22.7
 
 require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/klauspost/compress v1.18.0
+	github.com/stretchr/testify v1.11.1
 	golang.org/x/net v0.35.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/text v0.22.0 // indirect
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
@@ -0,0 +1,255 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/output"
+)
+
+const (
+	percentPlaces = 4
+	percentScale  = 100
+)
+
+var (
+	ErrBadRule    = errors.New("invalid alert rule")
+	ErrNoPrevious = errors.New("rule needs a previous snapshot")
+)
+
+type kind int
+
+const (
+	kindAbove kind = iota
+	kindAtLeast
+	kindBelow
+	kindAtMost
+	kindChanged
+	kindRose
+	kindFell
+)
+
+//nolint:gochecknoglobals
+var (
+	levelPattern  = regexp.MustCompile(`^([A-Za-z]{3})\s*(>=|<=|>|<)\s*(\S+)$`)
+	changePattern = regexp.MustCompile(
+		`^(?i)([a-z]{3})\s+(changed|rose|fell)\s+by\s+more\s+than\s+([^\s%]+)\s*(%?)(?:\s+since\s+(?:the\s+)?previous\s+snapshot)?$`)
+
+	operators = map[string]kind{">": kindAbove, ">=": kindAtLeast, "<": kindBelow, "<=": kindAtMost}
+	movements = map[string]kind{"changed": kindChanged, "rose": kindRose, "fell": kindFell}
+)
+
+// Rule is one condition on the per-unit rate of a currency, written as
+// "USD > 100" (also >=, <, <=) or "EUR changed by more than 2% since previous
+// snapshot" (also "rose" and "fell"; without % the change is absolute).
+type Rule struct {
+	Text      string
+	CharCode  string
+	kind      kind
+	threshold decimal.Decimal
+	percent   bool
+}
+
+func Parse(raw string) (Rule, error) {
+	text := strings.Join(strings.Fields(raw), " ")
+	result := Rule{Text: text, CharCode: "", kind: kindAbove, threshold: decimal.Decimal{}, percent: false}
+
+	var number string
+
+	if match := levelPattern.FindStringSubmatch(text); match != nil {
+		result.CharCode, result.kind, number = match[1], operators[match[2]], match[3]
+	} else if match = changePattern.FindStringSubmatch(text); match != nil {
+		result.CharCode, result.kind, number = match[1], movements[strings.ToLower(match[2])], match[3]
+		result.percent = match[4] != ""
+	} else {
+		return result, fmt.Errorf("%w: %q, expected e.g. \"USD > 100\" or \"EUR changed by more than 2%%\"",
+			ErrBadRule, raw)
+	}
+
+	threshold, err := decimal.Parse(number)
+	if err != nil {
+		return result, fmt.Errorf("%w: %q: %w", ErrBadRule, raw, err)
+	}
+
+	if result.kind >= kindChanged && threshold.Sign() < 0 {
+		return result, fmt.Errorf("%w: %q: the change must not be negative", ErrBadRule, raw)
+	}
+
+	result.CharCode = strings.ToUpper(result.CharCode)
+	result.threshold = threshold
+
+	return result, nil
+}
+
+func (obj *Rule) UnmarshalText(text []byte) error {
+	rule, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*obj = rule
+
+	return nil
+}
+
+func (obj Rule) MarshalText() ([]byte, error) {
+	return []byte(obj.Text), nil
+}
+
+// Relative reports whether the rule compares against the previous snapshot.
+func (obj *Rule) Relative() bool {
+	return obj.kind >= kindChanged
+}
+
+// Alert is a rule that fired. Previous and Change are only set for relative rules.
+type Alert struct {
+	Rule     string           `json:"rule"`
+	CharCode string           `json:"char_code"`
+	Rate     decimal.Decimal  `json:"rate"`
+	Previous *decimal.Decimal `json:"previous,omitempty"`
+	Change   *decimal.Decimal `json:"change,omitempty"`
+	Message  string           `json:"message"`
+}
+
+func optional(value *decimal.Decimal) any {
+	if value == nil {
+		return nil
+	}
+
+	return *value
+}
+
+func (obj *Alert) Record() output.Record {
+	return output.Record{
+		{Name: "rule", Value: obj.Rule},
+		{Name: "char_code", Value: obj.CharCode},
+		{Name: "rate", Value: obj.Rate},
+		{Name: "previous", Value: optional(obj.Previous)},
+		{Name: "change", Value: optional(obj.Change)},
+		{Name: "message", Value: obj.Message},
+	}
+}
+
+func (obj *Rule) level(rate decimal.Decimal) bool {
+	order := rate.Cmp(obj.threshold)
+
+	switch obj.kind {
+	case kindAbove:
+		return order > 0
+	case kindAtLeast:
+		return order >= 0
+	case kindBelow:
+		return order < 0
+	default:
+		return order <= 0
+	}
+}
+
+func (obj *Rule) movement(rate, previous decimal.Decimal) (decimal.Decimal, bool, error) {
+	change := rate.Sub(previous)
+
+	if obj.percent {
+		ratio, err := change.Div(previous, percentPlaces+2) //nolint:mnd
+		if err != nil {
+			return change, false, fmt.Errorf("cannot compute the change of %s: %w", obj.CharCode, err)
+		}
+
+		change = ratio.Mul(decimal.FromInt(percentScale)).Round(percentPlaces).TrimZeros(0)
+	}
+
+	switch obj.kind {
+	case kindRose:
+		return change, change.Cmp(obj.threshold) > 0, nil
+	case kindFell:
+		return change, change.Neg().Cmp(obj.threshold) > 0, nil
+	default:
+		return change, change.Abs().Cmp(obj.threshold) > 0, nil
+	}
+}
+
+// Check evaluates the rule. Currencies missing from either snapshot never fire;
+// relative rules fail with ErrNoPrevious when previous is nil.
+func (obj *Rule) Check(current, previous *currency.Rates) (Alert, bool, error) {
+	item, found := current.Find(obj.CharCode)
+	if !found {
+		return Alert{}, false, nil
+	}
+
+	result := Alert{obj.Text, obj.CharCode, item.Rate, nil, nil, ""}
+
+	if !obj.Relative() {
+		result.Message = fmt.Sprintf("%s is %s (%s)", obj.CharCode, item.Rate, obj.Text)
+
+		return result, obj.level(item.Rate), nil
+	}
+
+	if previous == nil {
+		return result, false, fmt.Errorf("%w: %q", ErrNoPrevious, obj.Text)
+	}
+
+	before, found := previous.Find(obj.CharCode)
+	if !found {
+		return result, false, nil
+	}
+
+	change, fired, err := obj.movement(item.Rate, before.Rate)
+	if err != nil {
+		return result, false, err
+	}
+
+	unit := ""
+	if obj.percent {
+		unit = "%"
+	}
+
+	result.Previous, result.Change = &before.Rate, &change
+	result.Message = fmt.Sprintf("%s moved from %s to %s, %s%s (%s)", obj.CharCode, before.Rate, item.Rate,
+		signed(change), unit, obj.Text)
+
+	return result, fired, nil
+}
+
+func signed(value decimal.Decimal) string {
+	if value.Sign() > 0 {
+		return "+" + value.String()
+	}
+
+	return value.String()
+}
+
+// Evaluate returns the alerts fired by the rules, in rule order.
+func Evaluate(rules []Rule, current, previous *currency.Rates) ([]Alert, error) {
+	var result []Alert
+
+	for idx := range rules {
+		item, fired, err := rules[idx].Check(current, previous)
+		if err != nil {
+			return result, err
+		}
+
+		if fired {
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
+
+func Columns() []string {
+	var alert Alert
+
+	return alert.Record().Names()
+}
+
+func Records(alerts []Alert) []output.Record {
+	records := make([]output.Record, len(alerts))
+	for idx := range alerts {
+		records[idx] = alerts[idx].Record()
+	}
+
+	return records
+}
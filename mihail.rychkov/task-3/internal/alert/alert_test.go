@@ -0,0 +1,132 @@
+package alert_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/alert"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rates(date string, values map[string]string) currency.Rates {
+	result := currency.Rates{Date: date}
+
+	for code, value := range values {
+		result.Data = append(result.Data, currency.Currency{CharCode: code, Nominal: 1, Rate: decimal.MustParse(value)})
+	}
+
+	return result
+}
+
+func rules(t *testing.T, texts ...string) []alert.Rule {
+	t.Helper()
+
+	result := make([]alert.Rule, len(texts))
+
+	for idx, text := range texts {
+		rule, err := alert.Parse(text)
+		require.NoError(t, err)
+
+		result[idx] = rule
+	}
+
+	return result
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	rule, err := alert.Parse("  eur  changed by more than 2,5%   since previous snapshot")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", rule.CharCode)
+	assert.True(t, rule.Relative())
+	assert.Equal(t, "eur changed by more than 2,5% since previous snapshot", rule.Text)
+
+	rule, err = alert.Parse("USD>=100")
+	require.NoError(t, err)
+	assert.False(t, rule.Relative())
+
+	for _, text := range []string{"USD = 100", "USD > lots", "US > 1", "EUR rose by more than -1%", "EUR doubled"} {
+		_, err = alert.Parse(text)
+		require.ErrorIs(t, err, alert.ErrBadRule, text)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	previous := rates("01.03.2024", map[string]string{"USD": "90", "EUR": "100", "CNY": "12.5"})
+	current := rates("02.03.2024", map[string]string{"USD": "101.5", "EUR": "97.9", "CNY": "12.6", "GBP": "115"})
+
+	set := rules(t, "USD > 100", "USD < 100", "EUR changed by more than 2%", "EUR rose by more than 2%",
+		"CNY changed by more than 0.05", "GBP changed by more than 1%", "JPY > 0")
+
+	alerts, err := alert.Evaluate(set, &current, &previous)
+	require.NoError(t, err)
+	require.Len(t, alerts, 3)
+
+	assert.Equal(t, "USD > 100", alerts[0].Rule)
+	assert.Nil(t, alerts[0].Previous)
+	assert.Equal(t, "EUR", alerts[1].CharCode)
+	assert.Equal(t, "-2.1", alerts[1].Change.String())
+	assert.Equal(t, "EUR moved from 100 to 97.9, -2.1% (EUR changed by more than 2%)", alerts[1].Message)
+	assert.Equal(t, "0.1", alerts[2].Change.String())
+
+	_, err = alert.Evaluate(set, &current, nil)
+	require.ErrorIs(t, err, alert.ErrNoPrevious)
+
+	alerts, err = alert.Evaluate(set[:2], &current, nil)
+	require.NoError(t, err)
+	assert.Len(t, alerts, 1)
+}
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+
+	var received struct {
+		Date   string `json:"date"`
+		Alerts []struct {
+			Rule     string       `json:"rule"`
+			Rate     json.Number  `json:"rate"`
+			Previous *json.Number `json:"previous"`
+		} `json:"alerts"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+		if request.URL.Path == "/broken" {
+			writer.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&received))
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	current := rates("02.03.2024", map[string]string{"USD": "101.5"})
+
+	alerts, err := alert.Evaluate(rules(t, "USD > 100"), &current, nil)
+	require.NoError(t, err)
+
+	hook := alert.Webhook{URL: server.URL + "/alerts", Timeout: 0, HTTPClient: server.Client()}
+	require.NoError(t, hook.Send(context.Background(), current.Date, alerts))
+
+	assert.Equal(t, "02.03.2024", received.Date)
+	require.Len(t, received.Alerts, 1)
+	assert.Equal(t, "USD > 100", received.Alerts[0].Rule)
+	assert.Equal(t, json.Number("101.5"), received.Alerts[0].Rate)
+	assert.Nil(t, received.Alerts[0].Previous)
+
+	hook.URL = server.URL + "/broken"
+	require.ErrorIs(t, hook.Send(context.Background(), current.Date, alerts), alert.ErrWebhookStatus)
+}
@@ -0,0 +1,75 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultTimeout = time.Second * 10
+
+var ErrWebhookStatus = errors.New("webhook rejected the alerts")
+
+// Webhook posts fired alerts as a JSON document:
+// {"date": "02.03.2024", "alerts": [{"rule": ..., "char_code": ..., ...}]}.
+type Webhook struct {
+	URL        string
+	Timeout    time.Duration
+	HTTPClient *http.Client
+}
+
+type payload struct {
+	Date   string  `json:"date"`
+	Alerts []Alert `json:"alerts"`
+}
+
+func (obj *Webhook) Send(ctx context.Context, date string, alerts []Alert) error {
+	var body bytes.Buffer
+
+	encoder := json.NewEncoder(&body)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(payload{date, alerts})
+	if err != nil {
+		return fmt.Errorf("failed to serialize alerts: %w", err)
+	}
+
+	timeout := obj.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, obj.URL, &body)
+	if err != nil {
+		return fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	client := obj.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("cannot post alerts: %w", err)
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %s", ErrWebhookStatus, response.Status)
+	}
+
+	return nil
+}
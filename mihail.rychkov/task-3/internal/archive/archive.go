@@ -0,0 +1,191 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/output"
+)
+
+const (
+	fileLayout    = time.DateOnly
+	fileExtension = ".json"
+	dirMode       = os.FileMode(0o755)
+	fileMode      = os.FileMode(0o644)
+)
+
+var (
+	ErrNotFound = errors.New("no published rate")
+	ErrEmpty    = errors.New("archive is empty")
+)
+
+type Archive struct {
+	Dir string
+}
+
+type Observation struct {
+	Published time.Time
+	Currency  currency.Currency
+}
+
+func (obj *Archive) path(day time.Time) string {
+	return filepath.Join(obj.Dir, day.Format(fileLayout)+fileExtension)
+}
+
+// Ingest stores the snapshot under its ValCurs date, replacing an earlier copy of the same day.
+func (obj *Archive) Ingest(rates currency.Rates) (time.Time, error) {
+	day, err := rates.Day()
+	if err != nil {
+		return day, err
+	}
+
+	serialized, err := json.Marshal(rates)
+	if err != nil {
+		return day, fmt.Errorf("failed to serialize snapshot: %w", err)
+	}
+
+	opts := output.FileOptions{FileMode: fileMode, DirMode: dirMode, Backup: false, Protected: nil}
+
+	err = output.WriteBytes(obj.path(day), serialized, opts)
+	if err != nil {
+		return day, fmt.Errorf("cannot write archive entry: %w", err)
+	}
+
+	return day, nil
+}
+
+func (obj *Archive) Dates() ([]time.Time, error) {
+	entries, err := os.ReadDir(obj.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot list archive: %w", err)
+	}
+
+	dates := make([]time.Time, 0, len(entries))
+
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), fileExtension)
+		if entry.IsDir() || !found {
+			continue
+		}
+
+		day, err := time.Parse(fileLayout, name)
+		if err != nil {
+			continue
+		}
+
+		dates = append(dates, day)
+	}
+
+	slices.SortFunc(dates, func(lhs, rhs time.Time) int { return lhs.Compare(rhs) })
+
+	return dates, nil
+}
+
+func (obj *Archive) Load(day time.Time) (currency.Rates, error) {
+	var result currency.Rates
+
+	serialized, err := os.ReadFile(obj.path(day))
+	if err != nil {
+		return result, fmt.Errorf("cannot read archive entry: %w", err)
+	}
+
+	err = json.Unmarshal(serialized, &result)
+	if err != nil {
+		return result, fmt.Errorf("failed to parse archive entry: %w", err)
+	}
+
+	return result, nil
+}
+
+// AsOf returns the rate from the last snapshot published on or before the given
+// day, so weekends and holidays get the rates in force then. A currency missing
+// from that snapshot was not quoted that day and is not looked up further back.
+func (obj *Archive) AsOf(charCode string, day time.Time) (Observation, error) {
+	dates, err := obj.Dates()
+	if err != nil {
+		return Observation{}, err
+	}
+
+	if len(dates) == 0 {
+		return Observation{}, ErrEmpty
+	}
+
+	for idx := len(dates) - 1; idx >= 0; idx-- {
+		if dates[idx].After(day) {
+			continue
+		}
+
+		rates, err := obj.Load(dates[idx])
+		if err != nil {
+			return Observation{}, err
+		}
+
+		item, found := rates.Find(charCode)
+		if !found {
+			break
+		}
+
+		return Observation{dates[idx], item}, nil
+	}
+
+	return Observation{}, fmt.Errorf("%w for %s as of %s", ErrNotFound, charCode, day.Format(fileLayout))
+}
+
+// Before returns the latest snapshot published strictly before the given day.
+func (obj *Archive) Before(day time.Time) (currency.Rates, error) {
+	dates, err := obj.Dates()
+	if err != nil {
+		return currency.Rates{}, err
+	}
+
+	for idx := len(dates) - 1; idx >= 0; idx-- {
+		if dates[idx].Before(day) {
+			return obj.Load(dates[idx])
+		}
+	}
+
+	return currency.Rates{}, fmt.Errorf("%w before %s", ErrNotFound, day.Format(fileLayout))
+}
+
+// Series lists every published rate of the currency between from and to inclusive;
+// zero bounds are open.
+func (obj *Archive) Series(charCode string, from, to time.Time) ([]Observation, error) {
+	dates, err := obj.Dates()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Observation
+
+	for _, day := range dates {
+		if (!from.IsZero() && day.Before(from)) || (!to.IsZero() && day.After(to)) {
+			continue
+		}
+
+		rates, err := obj.Load(day)
+		if err != nil {
+			return nil, err
+		}
+
+		if item, found := rates.Find(charCode); found {
+			result = append(result, Observation{day, item})
+		}
+	}
+
+	return result, nil
+}
+
+func (obj *Observation) Record() output.Record {
+	return append(output.Record{{Name: "date", Value: obj.Published.Format(fileLayout)}}, obj.Currency.Record()...)
+}
@@ -0,0 +1,81 @@
+package archive_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/archive"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshot(date string, value string) currency.Rates {
+	var rates currency.Rates
+
+	rates.Date = date
+	rates.Data = []currency.Currency{{
+		ID: "R01235", NumCode: 840, CharCode: "USD", Nominal: 1, Name: "Dollar",
+		Value: decimal.MustParse(value), VunitRate: decimal.Decimal{}, Rate: decimal.MustParse(value),
+	}}
+
+	return rates
+}
+
+func day(raw string) time.Time {
+	parsed, _ := time.Parse(time.DateOnly, raw)
+
+	return parsed
+}
+
+func TestAsOfAndSeries(t *testing.T) {
+	t.Parallel()
+
+	store := archive.Archive{Dir: t.TempDir()}
+
+	for date, value := range map[string]string{"05.01.2024": "90,1", "09.01.2024": "91,2", "10.01.2024": "92,3"} {
+		_, err := store.Ingest(snapshot(date, value))
+		require.NoError(t, err)
+	}
+
+	observation, err := store.AsOf("usd", day("2024-01-07"))
+	require.NoError(t, err)
+	assert.Equal(t, day("2024-01-05"), observation.Published)
+	assert.Equal(t, "90.1", observation.Currency.Value.String())
+
+	_, err = store.AsOf("USD", day("2024-01-01"))
+	require.ErrorIs(t, err, archive.ErrNotFound)
+
+	_, err = store.AsOf("EUR", day("2024-01-10"))
+	require.ErrorIs(t, err, archive.ErrNotFound)
+
+	delisted := snapshot("11.01.2024", "93,4")
+	delisted.Data[0].CharCode = "EUR"
+
+	_, err = store.Ingest(delisted)
+	require.NoError(t, err)
+
+	_, err = store.AsOf("USD", day("2024-01-13"))
+	require.ErrorIs(t, err, archive.ErrNotFound)
+
+	observation, err = store.AsOf("USD", day("2024-01-10"))
+	require.NoError(t, err)
+	assert.Equal(t, day("2024-01-10"), observation.Published)
+
+	series, err := store.Series("USD", day("2024-01-06"), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.Equal(t, day("2024-01-09"), series[0].Published)
+	assert.Equal(t, "92.3", series[1].Currency.Rate.String())
+
+	previous, err := store.Before(day("2024-01-10"))
+	require.NoError(t, err)
+	assert.Equal(t, "09.01.2024", previous.Date)
+
+	_, err = store.Before(day("2024-01-05"))
+	require.ErrorIs(t, err, archive.ErrNotFound)
+
+	_, err = store.Ingest(currency.Rates{})
+	require.ErrorIs(t, err, currency.ErrNoDate)
+}
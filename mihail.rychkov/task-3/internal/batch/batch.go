@@ -0,0 +1,280 @@
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/compress"
+)
+
+type Status string
+
+const (
+	StatusConverted Status = "converted"
+	StatusUnchanged Status = "unchanged"
+	StatusFailed    Status = "failed"
+)
+
+var (
+	ErrNoInputs  = errors.New("no input files matched")
+	ErrCollision = errors.New("output name is used by another input")
+)
+
+type Job struct {
+	Input  string
+	Output string
+}
+
+// Entry is the manifest line of one input. Hash covers the input content and
+// the conversion fingerprint, so a file is converted again when either changes.
+type Entry struct {
+	Input      string  `json:"input"`
+	Output     string  `json:"output"`
+	Hash       string  `json:"hash,omitempty"`
+	Status     Status  `json:"status"`
+	Currencies int     `json:"currencies"`
+	Seconds    float64 `json:"seconds"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type Manifest struct {
+	Started   time.Time `json:"started"`
+	Seconds   float64   `json:"seconds"`
+	Files     int       `json:"files"`
+	Converted int       `json:"converted"`
+	Unchanged int       `json:"unchanged"`
+	Failed    int       `json:"failed"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// ConvertFunc converts one job and returns the number of currencies written.
+type ConvertFunc func(ctx context.Context, job Job) (int, error)
+
+type Runner struct {
+	Workers     int
+	Fingerprint []byte
+	// Previous is the manifest of the last run; unchanged inputs whose output still exists are skipped.
+	Previous *Manifest
+}
+
+// Expand lists the regular files of a directory or the matches of a glob, sorted.
+func Expand(pattern string) ([]string, error) {
+	var matches []string
+
+	info, err := os.Stat(pattern)
+	if err == nil && info.IsDir() {
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list input directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				matches = append(matches, filepath.Join(pattern, entry.Name()))
+			}
+		}
+	} else {
+		matches, err = filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input pattern: %w", err)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoInputs, pattern)
+	}
+
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+// Jobs maps every input onto outputDir, replacing its extension with ext;
+// compression extensions go too, so "a.xml.gz" becomes "a"+ext.
+func Jobs(inputs []string, outputDir string, ext string) []Job {
+	jobs := make([]Job, len(inputs))
+
+	for idx, input := range inputs {
+		_, name := compress.FromPath(filepath.Base(input))
+		jobs[idx] = Job{input, filepath.Join(outputDir, name[:len(name)-len(filepath.Ext(name))]+ext)}
+	}
+
+	return jobs
+}
+
+func (obj *Runner) hash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read input: %w", err)
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	digest.Write(obj.Fingerprint)
+
+	_, err = io.Copy(digest, file)
+	if err != nil {
+		return "", fmt.Errorf("cannot read input: %w", err)
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// unchanged looks the entry up in the previous manifest and carries its currency count over.
+func (obj *Runner) unchanged(entry *Entry) bool {
+	if obj.Previous == nil {
+		return false
+	}
+
+	for _, previous := range obj.Previous.Entries {
+		if previous.Input != entry.Input || previous.Output != entry.Output {
+			continue
+		}
+
+		if _, err := os.Stat(entry.Output); err != nil || previous.Status == StatusFailed || previous.Hash != entry.Hash {
+			return false
+		}
+
+		entry.Currencies = previous.Currencies
+
+		return true
+	}
+
+	return false
+}
+
+func (obj *Runner) process(ctx context.Context, job Job, convert ConvertFunc) Entry {
+	started := time.Now()
+	entry := Entry{job.Input, job.Output, "", StatusFailed, 0, 0, ""}
+
+	defer func() { entry.Seconds = time.Since(started).Seconds() }()
+
+	hash, err := obj.hash(job.Input)
+	if err != nil {
+		entry.Error = err.Error()
+
+		return entry
+	}
+
+	entry.Hash = hash
+
+	if obj.unchanged(&entry) {
+		entry.Status = StatusUnchanged
+
+		return entry
+	}
+
+	entry.Currencies, err = convert(ctx, job)
+	if err != nil {
+		entry.Error = err.Error()
+
+		return entry
+	}
+
+	entry.Status = StatusConverted
+
+	return entry
+}
+
+// Run converts the jobs with at most Workers at a time. A failing file never
+// stops the others; its error ends up in the manifest.
+func (obj *Runner) Run(ctx context.Context, jobs []Job, convert ConvertFunc) Manifest {
+	workers := obj.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	manifest := Manifest{Started: time.Now(), Seconds: 0, Files: len(jobs), Converted: 0, Unchanged: 0, Failed: 0,
+		Entries: make([]Entry, len(jobs))}
+	owners := make(map[string]string, len(jobs))
+	queue := make(chan int)
+
+	var group sync.WaitGroup
+
+	for range workers {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			for idx := range queue {
+				manifest.Entries[idx] = obj.process(ctx, jobs[idx], convert)
+			}
+		}()
+	}
+
+	for idx, job := range jobs {
+		if owner, taken := owners[job.Output]; taken {
+			manifest.Entries[idx] = Entry{job.Input, job.Output, "", StatusFailed, 0, 0,
+				fmt.Sprintf("%v: %s", ErrCollision, owner)}
+
+			continue
+		}
+
+		owners[job.Output] = job.Input
+
+		select {
+		case queue <- idx:
+		case <-ctx.Done():
+			manifest.Entries[idx] = Entry{job.Input, job.Output, "", StatusFailed, 0, 0, ctx.Err().Error()}
+		}
+	}
+
+	close(queue)
+	group.Wait()
+
+	for _, entry := range manifest.Entries {
+		switch entry.Status {
+		case StatusConverted:
+			manifest.Converted++
+		case StatusUnchanged:
+			manifest.Unchanged++
+		case StatusFailed:
+			manifest.Failed++
+		}
+	}
+
+	manifest.Seconds = time.Since(manifest.Started).Seconds()
+
+	return manifest
+}
+
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil //nolint:nilnil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read manifest: %w", err)
+	}
+
+	var manifest Manifest
+
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+func (obj *Manifest) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(obj, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize manifest: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,115 @@
+package batch_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/batch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBroken = errors.New("broken input")
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	for _, name := range []string{"2024-03-01.xml", "2024-03-02.xml", "broken.xml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, name), []byte(name), 0o600))
+	}
+
+	inputs, err := batch.Expand(inputDir)
+	require.NoError(t, err)
+	require.Len(t, inputs, 3)
+
+	globbed, err := batch.Expand(filepath.Join(inputDir, "2024-*.xml"))
+	require.NoError(t, err)
+	assert.Len(t, globbed, 2)
+
+	_, err = batch.Expand(filepath.Join(inputDir, "*.csv"))
+	require.ErrorIs(t, err, batch.ErrNoInputs)
+
+	jobs := batch.Jobs(inputs, outputDir, ".json")
+	assert.Equal(t, filepath.Join(outputDir, "2024-03-01.json"), jobs[0].Output)
+	assert.Equal(t, filepath.Join(outputDir, "old.json"), batch.Jobs([]string{"old.xml.gz"}, outputDir, ".json")[0].Output)
+
+	var calls atomic.Int32
+
+	convert := func(_ context.Context, job batch.Job) (int, error) {
+		calls.Add(1)
+
+		if filepath.Base(job.Input) == "broken.xml" {
+			return 0, errBroken
+		}
+
+		return 2, os.WriteFile(job.Output, []byte("{}"), 0o600)
+	}
+
+	runner := batch.Runner{Workers: 2, Fingerprint: []byte("v1"), Previous: nil}
+	manifest := runner.Run(context.Background(), jobs, convert)
+
+	assert.Equal(t, 3, manifest.Files)
+	assert.Equal(t, 2, manifest.Converted)
+	assert.Equal(t, 1, manifest.Failed)
+	assert.Equal(t, 2, manifest.Entries[1].Currencies)
+	assert.Equal(t, errBroken.Error(), manifest.Entries[2].Error)
+
+	data, err := manifest.Marshal()
+	require.NoError(t, err)
+
+	path := filepath.Join(outputDir, "manifest.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	previous, err := batch.LoadManifest(path)
+	require.NoError(t, err)
+
+	runner.Previous = previous
+	manifest = runner.Run(context.Background(), jobs, convert)
+	assert.Equal(t, 2, manifest.Unchanged)
+	assert.Equal(t, 2, manifest.Entries[0].Currencies)
+	assert.Equal(t, int32(4), calls.Load())
+
+	require.NoError(t, os.WriteFile(inputs[0], []byte("changed"), 0o600))
+
+	runner.Fingerprint = []byte("v1")
+	manifest = runner.Run(context.Background(), jobs, convert)
+	assert.Equal(t, batch.StatusConverted, manifest.Entries[0].Status)
+	assert.Equal(t, batch.StatusUnchanged, manifest.Entries[1].Status)
+
+	runner.Fingerprint = []byte("v2")
+	manifest = runner.Run(context.Background(), jobs, convert)
+	assert.Equal(t, 2, manifest.Converted)
+
+	missing, err := batch.LoadManifest(filepath.Join(outputDir, "absent.json"))
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestCollision(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inputs := []string{filepath.Join(dir, "a", "rates.xml"), filepath.Join(dir, "b", "rates.xml")}
+
+	for _, input := range inputs {
+		require.NoError(t, os.MkdirAll(filepath.Dir(input), 0o700))
+		require.NoError(t, os.WriteFile(input, nil, 0o600))
+	}
+
+	jobs := batch.Jobs(inputs, filepath.Join(dir, "out"), ".csv")
+	runner := batch.Runner{Workers: 1, Fingerprint: nil, Previous: nil}
+
+	manifest := runner.Run(context.Background(), jobs, func(context.Context, batch.Job) (int, error) {
+		return 0, nil
+	})
+
+	assert.Equal(t, 1, manifest.Failed)
+	assert.Contains(t, manifest.Entries[1].Error, batch.ErrCollision.Error())
+}
@@ -0,0 +1,307 @@
+package cbr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/Rychmick/task-3/internal/xml"
+)
+
+const (
+	DefaultBaseURL = "https://www.cbr.ru/scripts/XML_daily.asp"
+
+	defaultTimeout    = time.Second * 10
+	defaultRetryDelay = time.Millisecond * 500
+	cacheDirMode      = os.FileMode(0o755)
+	cacheFileMode     = os.FileMode(0o644)
+	queryDateLayout   = "02/01/2006"
+	cacheDateLayout   = "2006-01-02"
+	documentLayout    = "02.01.2006"
+	cacheExtension    = ".xml"
+)
+
+var (
+	ErrUnexpectedStatus = errors.New("unexpected http status")
+	ErrRequestRejected  = errors.New("request rejected")
+	ErrInvalidDocument  = errors.New("response is not a ValCurs document")
+	ErrNoCachedCopy     = errors.New("no cached copy available")
+)
+
+type Origin string
+
+const (
+	OriginNetwork Origin = "network"
+	OriginCache   Origin = "cache"
+	OriginOffline Origin = "offline"
+)
+
+type Snapshot struct {
+	Body   []byte
+	Origin Origin
+}
+
+type Client struct {
+	BaseURL    string
+	Timeout    time.Duration
+	Retries    int
+	RetryDelay time.Duration
+	CacheDir   string
+	HTTPClient *http.Client
+}
+
+// documentKey checks that body is a ValCurs document and returns the cache key
+// of the day it was published for.
+func documentKey(body []byte) (string, error) {
+	var document struct {
+		XMLName struct{} `xml:"ValCurs"`
+		Date    string   `xml:"Date,attr"`
+	}
+
+	err := xml.Parse(bytes.NewReader(body), &document)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+
+	day, err := time.Parse(documentLayout, document.Date)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad date %q", ErrInvalidDocument, document.Date)
+	}
+
+	return day.Format(cacheDateLayout), nil
+}
+
+// latestKey returns the most recent day in the cache. The latest rates are
+// cached under the date of the document, so it is also their key.
+func (obj *Client) latestKey() string {
+	entries, err := os.ReadDir(obj.CacheDir)
+	if err != nil {
+		return ""
+	}
+
+	var latest string
+
+	for _, entry := range entries {
+		key, found := strings.CutSuffix(entry.Name(), cacheExtension)
+		if !found {
+			continue
+		}
+
+		if _, err := time.Parse(cacheDateLayout, key); err == nil && key > latest {
+			latest = key
+		}
+	}
+
+	return latest
+}
+
+func (obj *Client) cacheKey(date time.Time) string {
+	if date.IsZero() {
+		return obj.latestKey()
+	}
+
+	return date.Format(cacheDateLayout)
+}
+
+func (obj *Client) requestURL(date time.Time) (string, error) {
+	base := obj.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+
+	parsed, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url: %w", err)
+	}
+
+	if !date.IsZero() {
+		query := parsed.Query()
+		query.Set("date_req", date.Format(queryDateLayout))
+		parsed.RawQuery = query.Encode()
+	}
+
+	return parsed.String(), nil
+}
+
+func (obj *Client) readCache(key string) ([]byte, string) {
+	if obj.CacheDir == "" || key == "" {
+		return nil, ""
+	}
+
+	body, err := os.ReadFile(filepath.Join(obj.CacheDir, key+cacheExtension))
+	if err != nil {
+		return nil, ""
+	}
+
+	etag, _ := os.ReadFile(filepath.Join(obj.CacheDir, key+".etag"))
+
+	return body, string(etag)
+}
+
+func (obj *Client) writeCache(key string, body []byte, etag string) error {
+	if obj.CacheDir == "" {
+		return nil
+	}
+
+	opts := output.FileOptions{FileMode: cacheFileMode, DirMode: cacheDirMode, Backup: false, Protected: nil}
+
+	err := output.WriteBytes(filepath.Join(obj.CacheDir, key+cacheExtension), body, opts)
+	if err != nil {
+		return fmt.Errorf("cannot write cache file: %w", err)
+	}
+
+	err = output.WriteBytes(filepath.Join(obj.CacheDir, key+".etag"), []byte(etag), opts)
+	if err != nil {
+		return fmt.Errorf("cannot write cache file: %w", err)
+	}
+
+	return nil
+}
+
+type reply struct {
+	notModified bool
+	body        []byte
+	etag        string
+}
+
+func (obj *Client) attempt(ctx context.Context, target string, etag string) (reply, error) {
+	timeout := obj.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return reply{}, fmt.Errorf("cannot build request: %w", err)
+	}
+
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	httpClient := obj.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return reply{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified:
+		return reply{true, nil, etag}, nil
+	case response.StatusCode == http.StatusOK:
+	case response.StatusCode >= http.StatusInternalServerError:
+		return reply{}, fmt.Errorf("%w: %s", ErrUnexpectedStatus, response.Status)
+	default:
+		return reply{}, fmt.Errorf("%w: %s", ErrRequestRejected, response.Status)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return reply{}, fmt.Errorf("cannot read response body: %w", err)
+	}
+
+	return reply{false, body, response.Header.Get("ETag")}, nil
+}
+
+func (obj *Client) wait(ctx context.Context, try int) error {
+	delay := obj.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+
+	select {
+	case <-time.After(delay * time.Duration(try)):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("retry interrupted: %w", ctx.Err())
+	}
+}
+
+// Fetch downloads the rates for the given date (zero date means the latest published day).
+// When the service is unreachable the last cached copy for that date is returned instead;
+// for the latest rates that is the most recent cached day. Only network errors, 5xx
+// answers and bodies that are not a ValCurs document are retried.
+func (obj *Client) Fetch(ctx context.Context, date time.Time) (Snapshot, error) {
+	key := obj.cacheKey(date)
+	cached, etag := obj.readCache(key)
+
+	target, err := obj.requestURL(date)
+	if err != nil {
+		return Snapshot{nil, ""}, err
+	}
+
+	var lastErr error
+
+	for try := range obj.Retries + 1 {
+		if try > 0 {
+			lastErr = obj.wait(ctx, try)
+			if lastErr != nil {
+				break
+			}
+		}
+
+		var answer reply
+
+		answer, lastErr = obj.attempt(ctx, target, etag)
+		if errors.Is(lastErr, ErrRequestRejected) {
+			break
+		}
+
+		if lastErr != nil {
+			continue
+		}
+
+		if answer.notModified {
+			if cached != nil {
+				return Snapshot{cached, OriginCache}, nil
+			}
+
+			lastErr = fmt.Errorf("%w: not modified without a cached copy", ErrUnexpectedStatus)
+
+			continue
+		}
+
+		var documentDay string
+
+		documentDay, lastErr = documentKey(answer.body)
+		if lastErr != nil {
+			continue
+		}
+
+		if date.IsZero() {
+			key = documentDay
+		}
+
+		// the body is already validated, so a cache that cannot be written only costs the next offline run
+		err = obj.writeCache(key, answer.body, answer.etag)
+		if err != nil {
+			log.Println(err)
+		}
+
+		return Snapshot{answer.body, OriginNetwork}, nil
+	}
+
+	if cached != nil {
+		return Snapshot{cached, OriginOffline}, nil
+	}
+
+	return Snapshot{nil, ""}, fmt.Errorf("cannot fetch %s (%w): %w", target, ErrNoCachedCopy, lastErr)
+}
@@ -0,0 +1,163 @@
+package cbr_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/cbr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const body = `<ValCurs Date="05.01.2024"><Valute ID="R01235"><CharCode>USD</CharCode></Valute></ValCurs>`
+
+func newClient(url string, cacheDir string) cbr.Client {
+	return cbr.Client{
+		BaseURL:    url,
+		Timeout:    time.Second,
+		Retries:    2,
+		RetryDelay: time.Millisecond,
+		CacheDir:   cacheDir,
+		HTTPClient: nil,
+	}
+}
+
+func TestFetchCachesAndRevalidates(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+
+		assert.Equal(t, "05/01/2024", request.URL.Query().Get("date_req"))
+
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			writer.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		writer.Header().Set("ETag", `"v1"`)
+		_, _ = writer.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newClient(server.URL, t.TempDir())
+	date := time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+	snapshot, err := client.Fetch(context.Background(), date)
+	require.NoError(t, err)
+	assert.Equal(t, cbr.OriginNetwork, snapshot.Origin)
+	assert.Equal(t, body, string(snapshot.Body))
+
+	snapshot, err = client.Fetch(context.Background(), date)
+	require.NoError(t, err)
+	assert.Equal(t, cbr.OriginCache, snapshot.Origin)
+	assert.Equal(t, body, string(snapshot.Body))
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestFetchRetriesAndFallsBack(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) < 3 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = writer.Write([]byte(body))
+	}))
+
+	cacheDir := t.TempDir()
+	client := newClient(server.URL, cacheDir)
+
+	snapshot, err := client.Fetch(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, cbr.OriginNetwork, snapshot.Origin)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.FileExists(t, filepath.Join(cacheDir, "2024-01-05.xml"))
+
+	server.Close()
+
+	snapshot, err = client.Fetch(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, cbr.OriginOffline, snapshot.Origin)
+	assert.Equal(t, body, string(snapshot.Body))
+
+	client = newClient(server.URL, t.TempDir())
+
+	_, err = client.Fetch(context.Background(), time.Time{})
+	require.ErrorIs(t, err, cbr.ErrNoCachedCopy)
+}
+
+func TestFetchRejectsWithoutRetry(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		writer.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newClient(server.URL, t.TempDir())
+
+	_, err := client.Fetch(context.Background(), time.Time{})
+	require.ErrorIs(t, err, cbr.ErrRequestRejected)
+	require.ErrorIs(t, err, cbr.ErrNoCachedCopy)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestFetchDoesNotCacheInvalidBody(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = writer.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	client := newClient(server.URL, cacheDir)
+
+	_, err := client.Fetch(context.Background(), time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC))
+	require.ErrorIs(t, err, cbr.ErrInvalidDocument)
+	assert.Equal(t, int32(3), requests.Load())
+
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFetchKeepsBodyWhenCacheUnwritable(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	blocker := filepath.Join(t.TempDir(), "cache")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+
+	client := newClient(server.URL, blocker)
+
+	snapshot, err := client.Fetch(context.Background(), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, cbr.OriginNetwork, snapshot.Origin)
+	assert.Equal(t, body, string(snapshot.Body))
+}
@@ -0,0 +1,243 @@
+// Package compress reads gzip, zstd and zip inputs transparently and compresses
+// outputs according to their extension.
+package compress
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type Codec string
+
+const (
+	None Codec = ""
+	Gzip Codec = "gzip"
+	Zstd Codec = "zstd"
+	Zip  Codec = "zip"
+
+	// EntrySeparator picks a file inside a zip archive: "rates.zip#2024-03-01.xml".
+	EntrySeparator = "#"
+
+	magicLength = 4
+)
+
+var (
+	ErrNoEntry        = errors.New("no such file in the zip archive")
+	ErrAmbiguousEntry = errors.New("zip archive holds several files, pick one with " + EntrySeparator)
+	ErrZipStream      = errors.New("zip archives can only be read from files")
+)
+
+//nolint:gochecknoglobals
+var (
+	extensions = map[string]Codec{".gz": Gzip, ".gzip": Gzip, ".zst": Zstd, ".zstd": Zstd, ".zip": Zip}
+	magics     = map[Codec][]byte{
+		Gzip: {0x1f, 0x8b},
+		Zstd: {0x28, 0xb5, 0x2f, 0xfd},
+		Zip:  {'P', 'K', 0x03, 0x04},
+	}
+)
+
+// FromPath returns the codec implied by the extension and the path without it,
+// so "rates.xml.gz" gives Gzip and "rates.xml".
+func FromPath(path string) (Codec, string) {
+	ext := filepath.Ext(path)
+
+	codec, found := extensions[strings.ToLower(ext)]
+	if !found {
+		return None, path
+	}
+
+	return codec, strings.TrimSuffix(path, ext)
+}
+
+// Split separates a zip entry from the archive path. Paths of existing files
+// are never split, so file names containing the separator keep working.
+func Split(path string) (string, string) {
+	if _, err := os.Stat(path); err == nil {
+		return path, ""
+	}
+
+	archive, entry, found := strings.Cut(path, EntrySeparator)
+	if !found {
+		return path, ""
+	}
+
+	return archive, entry
+}
+
+func sniff(reader *bufio.Reader) Codec {
+	header, _ := reader.Peek(magicLength)
+
+	for codec, magic := range magics {
+		if bytes.HasPrefix(header, magic) {
+			return codec
+		}
+	}
+
+	return None
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (obj readCloser) Close() error {
+	return obj.close()
+}
+
+func decompress(codec Codec, reader io.Reader, closer func() error) (io.ReadCloser, error) {
+	switch codec {
+	case Gzip:
+		decoder, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip stream: %w", err)
+		}
+
+		return readCloser{decoder, func() error { return errors.Join(decoder.Close(), closer()) }}, nil
+	case Zstd:
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zstd stream: %w", err)
+		}
+
+		return readCloser{decoder, func() error { decoder.Close(); return closer() }}, nil //nolint:nlreturn
+	case Zip:
+		return nil, ErrZipStream
+	default:
+		return readCloser{reader, closer}, nil
+	}
+}
+
+// NewReader decompresses gzip and zstd streams, recognized by their magic
+// bytes, and passes anything else through.
+func NewReader(reader io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(reader)
+
+	return decompress(sniff(buffered), buffered, func() error { return nil })
+}
+
+// Open reads a plain, gzip or zstd file, or a file inside a zip archive. It
+// also returns the name of the content without compression extensions, such as
+// "rates.xml" for "rates.xml.gz" or the zip entry, for detecting its format.
+func Open(path string) (io.ReadCloser, string, error) {
+	archive, entry := Split(path)
+
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, "", err //nolint:wrapcheck
+	}
+
+	buffered := bufio.NewReader(file)
+
+	codec := sniff(buffered)
+	if codec == Zip {
+		file.Close()
+
+		return openEntry(archive, entry)
+	}
+
+	_, name := FromPath(archive)
+
+	reader, err := decompress(codec, buffered, file.Close)
+	if err != nil {
+		file.Close()
+
+		return nil, "", fmt.Errorf("%s: %w", archive, err)
+	}
+
+	return reader, name, nil
+}
+
+func openEntry(archive string, name string) (io.ReadCloser, string, error) {
+	zipped, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: invalid zip archive: %w", archive, err)
+	}
+
+	var (
+		chosen *zip.File
+		files  []string
+	)
+
+	for _, item := range zipped.File {
+		if item.FileInfo().IsDir() {
+			continue
+		}
+
+		files = append(files, item.Name)
+
+		if name == "" || item.Name == name {
+			chosen = item
+		}
+	}
+
+	switch {
+	case chosen == nil:
+		zipped.Close()
+
+		return nil, "", fmt.Errorf("%w: %s%s%s", ErrNoEntry, archive, EntrySeparator, name)
+	case name == "" && len(files) > 1:
+		zipped.Close()
+
+		return nil, "", fmt.Errorf("%w: %s has %s", ErrAmbiguousEntry, archive, strings.Join(files, ", "))
+	}
+
+	content, err := chosen.Open()
+	if err != nil {
+		zipped.Close()
+
+		return nil, "", fmt.Errorf("%s%s%s: %w", archive, EntrySeparator, chosen.Name, err)
+	}
+
+	return readCloser{content, func() error { return errors.Join(content.Close(), zipped.Close()) }}, chosen.Name, nil
+}
+
+type writeCloser struct {
+	io.Writer
+	close func() error
+}
+
+func (obj writeCloser) Close() error {
+	return obj.close()
+}
+
+// NewWriter compresses into writer according to the extension of path. A zip
+// archive gets a single entry named after path without ".zip". Close flushes
+// the compressed stream but leaves writer open.
+func NewWriter(path string, writer io.Writer) (io.WriteCloser, error) {
+	codec, inner := FromPath(path)
+
+	switch codec {
+	case Gzip:
+		return gzip.NewWriter(writer), nil
+	case Zstd:
+		encoder, err := zstd.NewWriter(writer)
+		if err != nil {
+			return nil, fmt.Errorf("cannot start zstd stream: %w", err)
+		}
+
+		return encoder, nil
+	case Zip:
+		archive := zip.NewWriter(writer)
+
+		entry, err := archive.Create(filepath.Base(inner))
+		if err != nil {
+			return nil, fmt.Errorf("cannot start zip archive: %w", err)
+		}
+
+		return writeCloser{entry, archive.Close}, nil
+	default:
+		return writeCloser{writer, func() error { return nil }}, nil
+	}
+}
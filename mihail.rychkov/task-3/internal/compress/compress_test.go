@@ -0,0 +1,106 @@
+package compress_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/compress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const content = "<ValCurs Date=\"02.03.2024\"/>"
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+
+	var buffer bytes.Buffer
+
+	writer, err := compress.NewWriter(path, &buffer)
+	require.NoError(t, err)
+
+	_, err = io.WriteString(writer, content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, os.WriteFile(path, buffer.Bytes(), 0o600))
+}
+
+func readFile(t *testing.T, path string) (string, string) {
+	t.Helper()
+
+	reader, name, err := compress.Open(path)
+	require.NoError(t, err, path)
+
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return string(data), name
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for path, expected := range map[string]string{
+		"rates.xml":     "rates.xml",
+		"rates.xml.gz":  "rates.xml",
+		"rates.xml.zst": "rates.xml",
+		"rates.xml.zip": "rates.xml",
+	} {
+		path = filepath.Join(dir, path)
+		writeFile(t, path)
+
+		data, name := readFile(t, path)
+		assert.Equal(t, content, data, path)
+		assert.Equal(t, expected, filepath.Base(name), path)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "rates.xml.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x1f, 0x8b}, raw[:2])
+
+	reader, err := compress.NewReader(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func TestZipEntries(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "archive.zip")
+
+	var buffer bytes.Buffer
+
+	archive := zip.NewWriter(&buffer)
+
+	for _, name := range []string{"2024-03-01.xml", "2024-03-02.xml"} {
+		entry, err := archive.Create(name)
+		require.NoError(t, err)
+
+		_, err = io.WriteString(entry, name)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, os.WriteFile(path, buffer.Bytes(), 0o600))
+
+	data, name := readFile(t, path+"#2024-03-02.xml")
+	assert.Equal(t, "2024-03-02.xml", data)
+	assert.Equal(t, "2024-03-02.xml", name)
+
+	_, _, err := compress.Open(path)
+	require.ErrorIs(t, err, compress.ErrAmbiguousEntry)
+
+	_, _, err = compress.Open(path + "#2024-03-03.xml")
+	require.ErrorIs(t, err, compress.ErrNoEntry)
+}
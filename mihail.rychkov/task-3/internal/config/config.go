@@ -1,29 +1,212 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"gopkg.in/yaml.v3"
+	"github.com/Rychmick/task-3/internal/alert"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/Rychmick/task-3/internal/selection"
 )
 
+const (
+	defaultArchiveDir = "archive"
+	defaultListen     = ":8080"
+	defaultRefresh    = time.Hour
+)
+
+var (
+	ErrInvalidFileMode = errors.New("invalid file mode")
+	ErrBadOverride     = errors.New("invalid setting override")
+)
+
+// FileMode is written in octal with a leading zero, e.g. "0644" or "0o755".
+// Plain numbers are rejected: TOML and JSON turn 0o644 into 420, which could
+// not be told apart from a mode written in octal without the zero.
+type FileMode os.FileMode
+
+func (obj *FileMode) UnmarshalText(text []byte) error {
+	raw := strings.ToLower(string(text))
+	if !strings.HasPrefix(raw, "0") {
+		return fmt.Errorf("%w: %q, write it in octal with a leading zero as a string, e.g. \"0644\"",
+			ErrInvalidFileMode, text)
+	}
+
+	mode, err := strconv.ParseUint(strings.TrimPrefix(raw, "0o"), 8, 32)
+	if err != nil || mode > uint64(os.ModePerm) {
+		return fmt.Errorf("%w: %q", ErrInvalidFileMode, text)
+	}
+
+	*obj = FileMode(mode)
+
+	return nil
+}
+
+type FetchSettings struct {
+	Enabled    bool          `yaml:"enabled"`
+	BaseURL    string        `yaml:"base-url"`
+	Date       string        `yaml:"date"`
+	Timeout    time.Duration `yaml:"timeout"`
+	Retries    int           `yaml:"retries"`
+	RetryDelay time.Duration `yaml:"retry-delay"`
+	CacheDir   string        `yaml:"cache-dir"`
+}
+
+// BatchSettings converts every file of a directory or glob into OutputDir.
+type BatchSettings struct {
+	Input     string `yaml:"input"`
+	OutputDir string `yaml:"output-dir"`
+	Workers   int    `yaml:"workers"`
+	Manifest  string `yaml:"manifest"`
+}
+
+// ISOSettings turn on ISO 4217 checks of parsed entries and the english_name
+// and minor_units output fields.
+type ISOSettings struct {
+	Validate bool `yaml:"validate"`
+	Enrich   bool `yaml:"enrich"`
+}
+
+// AlertSettings lists the rules checked after every export and what happens
+// when some fire: an alerts file, log lines on stderr, a webhook POST and/or
+// a distinct exit code.
+type AlertSettings struct {
+	Rules          []alert.Rule  `yaml:"rules"`
+	File           string        `yaml:"file"`
+	Stderr         bool          `yaml:"stderr"`
+	Webhook        string        `yaml:"webhook"`
+	WebhookTimeout time.Duration `yaml:"webhook-timeout"`
+	ExitCode       bool          `yaml:"exit-code"`
+}
+
+// ServerSettings configure the "serve" command; Refresh is how often fetched rates are reloaded.
+type ServerSettings struct {
+	Listen  string        `yaml:"listen"`
+	Refresh time.Duration `yaml:"refresh"`
+}
+
+type WatchSettings struct {
+	Interval time.Duration `yaml:"interval"`
+	Debounce time.Duration `yaml:"debounce"`
+	Poll     bool          `yaml:"poll"`
+}
+
+// ParseSettings controls how malformed Valute entries are handled: strict stops
+// at the first one, skip and default carry on. Above Threshold the output is
+// still written, but the run exits with the invalid input code.
+type ParseSettings struct {
+	Mode      currency.Mode `yaml:"mode"`
+	Threshold int           `yaml:"error-threshold"`
+	Report    string        `yaml:"report"`
+}
+
 type Settings struct {
 	InputFilePath  string `yaml:"input-file"`
+	InputFormat    string `yaml:"input-format"`
 	OutputFilePath string `yaml:"output-file"`
+	OutputFormat   string `yaml:"output-format"`
+	Precision      *int32 `yaml:"precision"`
+	ArchiveDir     string `yaml:"archive-dir"`
+	BaseCurrency   string `yaml:"base-currency"`
+	Streaming      bool   `yaml:"streaming"`
+
+	FileMode     FileMode `yaml:"file-mode"`
+	DirMode      FileMode `yaml:"dir-mode"`
+	BackupOutput bool     `yaml:"backup-output"`
+
+	Selection selection.Options `yaml:",inline"`
+
+	Fetch   FetchSettings  `yaml:"cbr"`
+	Parsing ParseSettings  `yaml:"parsing"`
+	Watch   WatchSettings  `yaml:"watch"`
+	Batch   BatchSettings  `yaml:"batch"`
+	Server  ServerSettings `yaml:"server"`
+	ISO     ISOSettings    `yaml:"iso4217"`
+	Alerts  AlertSettings  `yaml:"alerts"`
 }
 
-func Parse(configPath string) (Settings, error) {
+// Parse reads the config file, then applies CURRENCY_* environment variables
+// and finally the "key=value" overrides (usually from the command line), so the
+// later source always wins. An empty path skips the file. The result has its
+// defaults filled in and is validated.
+func Parse(configPath string, overrides ...string) (Settings, error) {
 	var result Settings
 
-	yamlFileData, err := os.ReadFile(configPath)
-	if err != nil {
-		return result, fmt.Errorf("cannot read config file: %w", err)
+	if configPath != "" {
+		err := decodeFile(configPath, &result)
+		if err != nil {
+			return result, err
+		}
 	}
 
-	err = yaml.Unmarshal(yamlFileData, &result)
+	err := applyEnv(&result, os.LookupEnv)
 	if err != nil {
-		return result, fmt.Errorf("failed to parse config file: %w", err)
+		return result, err
+	}
+
+	for _, override := range overrides {
+		key, value, found := strings.Cut(override, "=")
+		if !found {
+			return result, fmt.Errorf("%w: %q, expected key=value", ErrBadOverride, override)
+		}
+
+		err = Set(&result, strings.TrimSpace(key), value)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.applyDefaults()
+
+	return result, result.Validate()
+}
+
+func (obj *Settings) applyDefaults() {
+	if obj.ArchiveDir == "" {
+		obj.ArchiveDir = defaultArchiveDir
+	}
+
+	if obj.Parsing.Mode == "" {
+		obj.Parsing.Mode = currency.ModeStrict
+	}
+
+	if obj.Server.Listen == "" {
+		obj.Server.Listen = defaultListen
+	}
+
+	if obj.Server.Refresh == 0 {
+		obj.Server.Refresh = defaultRefresh
+	}
+}
+
+// Collector validates parsed entries according to the parsing and iso4217 settings.
+func (obj *Settings) Collector() *currency.Collector {
+	collector := currency.NewCollector(obj.Parsing.Mode)
+
+	if obj.ISO.Validate || obj.ISO.Enrich {
+		collector.CheckISO(obj.ISO.Enrich)
+	}
+
+	return collector
+}
+
+// FileOptions describes how output files are written; the input file is never overwritten.
+func (obj *Settings) FileOptions() output.FileOptions {
+	opts := output.FileOptions{
+		FileMode:  os.FileMode(obj.FileMode),
+		DirMode:   os.FileMode(obj.DirMode),
+		Backup:    obj.BackupOutput,
+		Protected: nil,
+	}
+
+	if !obj.Fetch.Enabled {
+		opts.Protected = []string{obj.InputFilePath}
 	}
 
-	return result, nil
+	return opts
 }
@@ -0,0 +1,154 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/alert"
+	"github.com/Rychmick/task-3/internal/config"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestParseFormats(t *testing.T) {
+	t.Parallel()
+
+	documents := map[string]string{
+		"config.yaml": "input-file: in.xml\noutput-format: csv\ncbr:\n  timeout: 5s\nsort: [char_code]\n",
+		"config.json": `{"input-file": "in.xml", "output-format": "csv", "cbr": {"timeout": "5s"}, "sort": ["char_code"]}`,
+		"config.toml": "input-file = \"in.xml\"\noutput-format = \"csv\"\nsort = [\"char_code\"]\n\n[cbr]\ntimeout = \"5s\"\n",
+	}
+
+	for name, document := range documents {
+		settings, err := config.Parse(writeConfig(t, name, document))
+		require.NoError(t, err, name)
+		assert.Equal(t, "in.xml", settings.InputFilePath, name)
+		assert.Equal(t, "csv", settings.OutputFormat, name)
+		assert.Equal(t, time.Second*5, settings.Fetch.Timeout, name)
+		assert.Equal(t, []string{"char_code"}, settings.Selection.Sort, name)
+		assert.Equal(t, "archive", settings.ArchiveDir, name)
+		assert.Equal(t, currency.ModeStrict, settings.Parsing.Mode, name)
+	}
+
+	_, err := config.Parse(writeConfig(t, "config.yaml", "input-file: in.xml\noutput-fiel: out.json\n"))
+	require.ErrorContains(t, err, "field output-fiel not found")
+
+	_, err = config.Parse(writeConfig(t, "config.toml", "[cbr]\nretrys = 3\n"))
+	require.ErrorContains(t, err, "field retrys not found")
+}
+
+func TestFileModes(t *testing.T) {
+	t.Parallel()
+
+	documents := map[string]string{
+		"config.yaml": "file-mode: 0644\ndir-mode: 0o750\n",
+		"config.json": `{"file-mode": "0644", "dir-mode": "0o750"}`,
+		"config.toml": "file-mode = \"0644\"\ndir-mode = \"0o750\"\n",
+	}
+
+	for name, document := range documents {
+		settings, err := config.Parse(writeConfig(t, name, document))
+		require.NoError(t, err, name)
+		assert.Equal(t, config.FileMode(0o644), settings.FileMode, name)
+		assert.Equal(t, config.FileMode(0o750), settings.DirMode, name)
+	}
+
+	for name, document := range map[string]string{
+		"config.yaml": "file-mode: 644\n",
+		"config.json": `{"file-mode": 420}`,
+		"config.toml": "file-mode = 0o644\n",
+	} {
+		_, err := config.Parse(writeConfig(t, name, document))
+		require.ErrorIs(t, err, config.ErrInvalidFileMode, name)
+	}
+}
+
+func TestOverrides(t *testing.T) { //nolint:paralleltest
+	t.Setenv("CURRENCY_OUTPUT_FILE", "env.json")
+	t.Setenv("CURRENCY_CBR_RETRY_DELAY", "250ms")
+	t.Setenv("CURRENCY_INCLUDE", "USD, EUR")
+	t.Setenv("CURRENCY_MIN_RATE", "1,5")
+
+	path := writeConfig(t, "config.yaml", "output-file: file.json\nprecision: 2\n")
+
+	settings, err := config.Parse(path, "output-file=flag.json", "parsing.mode=skip", "cbr.enabled=true")
+	require.NoError(t, err)
+	assert.Equal(t, "flag.json", settings.OutputFilePath)
+	assert.Equal(t, time.Millisecond*250, settings.Fetch.RetryDelay)
+	assert.Equal(t, []string{"USD", "EUR"}, settings.Selection.Include)
+	assert.Equal(t, "1.5", settings.Selection.MinRate.String())
+	assert.Equal(t, currency.ModeSkip, settings.Parsing.Mode)
+	assert.True(t, settings.Fetch.Enabled)
+	require.NotNil(t, settings.Precision)
+	assert.Equal(t, int32(2), *settings.Precision)
+
+	settings, err = config.Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, "env.json", settings.OutputFilePath)
+
+	_, err = config.Parse("", "no-such-key=1")
+	require.ErrorIs(t, err, config.ErrBadOverride)
+
+	_, err = config.Parse("", "cbr.retries=many")
+	require.ErrorIs(t, err, config.ErrBadOverride)
+
+	settings, err = config.Parse("", "alerts.rules=USD > 100, EUR changed by more than 2%", "alerts.stderr=true")
+	require.NoError(t, err)
+	require.Len(t, settings.Alerts.Rules, 2)
+	assert.Equal(t, "EUR", settings.Alerts.Rules[1].CharCode)
+
+	_, err = config.Parse("", "alerts.rules=USD is high")
+	require.ErrorIs(t, err, alert.ErrBadRule)
+
+	assert.Contains(t, config.Keys(), "cbr.retry-delay")
+	assert.Contains(t, config.Keys(), "sort")
+	assert.Equal(t, "CURRENCY_CBR_RETRY_DELAY", config.EnvName("cbr.retry-delay"))
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "config.yaml", "output-format: toml\nprecision: -1\nbase-currency: euro\n"+
+		"parsing:\n  mode: sloppy\ncbr:\n  base-url: ftp://example.com\n  date: 01.03.2024\nsort: [colour]\n"+
+		"alerts:\n  rules: [USD > 100]\n  webhook: example.com/hook\n")
+
+	_, err := config.Parse(path)
+
+	var validation *config.ValidationError
+
+	require.ErrorAs(t, err, &validation)
+	require.ErrorIs(t, err, config.ErrInvalidConfig)
+	assert.Len(t, validation.Problems, 8)
+	assert.Contains(t, err.Error(), "output-format: unknown output format")
+	assert.Contains(t, err.Error(), "cbr.date: expected YYYY-MM-DD")
+	assert.Contains(t, err.Error(), "alerts.webhook: expected an http(s) URL")
+
+	settings, err := config.Parse("")
+	require.NoError(t, err)
+
+	err = settings.Require(true, true)
+	require.ErrorAs(t, err, &validation)
+	assert.Equal(t, []string{
+		"input-file: required unless cbr.enabled is set (env CURRENCY_INPUT_FILE)",
+		"output-file: required (env CURRENCY_OUTPUT_FILE)",
+	}, validation.Problems)
+
+	settings.InputFilePath = filepath.Join(t.TempDir(), "missing.xml")
+	settings.OutputFilePath = "out.json"
+	require.ErrorContains(t, settings.Require(true, true), "no such file or directory")
+
+	settings.Fetch.Enabled = true
+	require.NoError(t, settings.Require(true, true))
+}
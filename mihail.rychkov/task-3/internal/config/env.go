@@ -0,0 +1,164 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const envPrefix = "CURRENCY_"
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem() //nolint:gochecknoglobals
+
+func yamlName(field reflect.StructField) (string, bool) {
+	name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+
+	return name, options == "inline"
+}
+
+// section reports whether a field is a nested group of settings rather than a value.
+func section(kind reflect.Type) bool {
+	return kind.Kind() == reflect.Struct && !reflect.PointerTo(kind).Implements(textUnmarshaler)
+}
+
+func collectKeys(kind reflect.Type, prefix string, keys *[]string) {
+	for idx := range kind.NumField() {
+		field := kind.Field(idx)
+
+		name, inline := yamlName(field)
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+
+		switch {
+		case inline:
+			collectKeys(field.Type, prefix, keys)
+		case section(field.Type):
+			collectKeys(field.Type, prefix+name+".", keys)
+		default:
+			*keys = append(*keys, prefix+name)
+		}
+	}
+}
+
+// Keys lists every setting as a dotted path, e.g. "input-file" or "cbr.timeout".
+func Keys() []string {
+	var keys []string
+
+	collectKeys(reflect.TypeOf(Settings{}), "", &keys) //nolint:exhaustruct
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// EnvName is the variable overriding a key: "cbr.retry-delay" becomes CURRENCY_CBR_RETRY_DELAY.
+func EnvName(key string) string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+func lookupField(value reflect.Value, parts []string) (reflect.Value, bool) {
+	kind := value.Type()
+
+	for idx := range kind.NumField() {
+		field := kind.Field(idx)
+
+		name, inline := yamlName(field)
+
+		switch {
+		case inline:
+			if found, ok := lookupField(value.Field(idx), parts); ok {
+				return found, true
+			}
+		case name != parts[0]:
+		case len(parts) == 1:
+			return value.Field(idx), true
+		case section(field.Type):
+			return lookupField(value.Field(idx), parts[1:])
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// listItem reports whether a list setting may be given as comma separated text.
+func listItem(kind reflect.Type) bool {
+	return kind.Kind() == reflect.String || reflect.PointerTo(kind).Implements(textUnmarshaler)
+}
+
+// Set changes one setting from its text form. Strings are taken verbatim, lists
+// of strings or text values (such as alert rules) may be comma separated and
+// everything else is read as a YAML scalar.
+func Set(settings *Settings, key string, raw string) error {
+	field, found := lookupField(reflect.ValueOf(settings).Elem(), strings.Split(key, "."))
+	if !found {
+		return fmt.Errorf("%w: unknown setting %q", ErrBadOverride, key)
+	}
+
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+
+		return nil
+	case field.Kind() == reflect.Slice && listItem(field.Type().Elem()) &&
+		!strings.HasPrefix(strings.TrimSpace(raw), "["):
+		return setList(field, key, raw)
+	}
+
+	err := yaml.Unmarshal([]byte(raw), field.Addr().Interface())
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrBadOverride, key, err)
+	}
+
+	return nil
+}
+
+func setList(field reflect.Value, key string, raw string) error {
+	kind := field.Type().Elem()
+	items := reflect.MakeSlice(field.Type(), 0, 0)
+
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		if kind.Kind() == reflect.String {
+			items = reflect.Append(items, reflect.ValueOf(item).Convert(kind))
+
+			continue
+		}
+
+		value := reflect.New(kind)
+
+		err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(item)) //nolint:forcetypeassert
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrBadOverride, key, err)
+		}
+
+		items = reflect.Append(items, value.Elem())
+	}
+
+	field.Set(items)
+
+	return nil
+}
+
+func applyEnv(settings *Settings, lookup func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		raw, found := lookup(EnvName(key))
+		if !found {
+			continue
+		}
+
+		err := Set(settings, key, raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", EnvName(key), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeFile reads YAML, JSON (a subset of YAML) or TOML depending on the
+// extension. Unknown keys are rejected in every format.
+func decodeFile(path string, result *Settings) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		data, err = tomlToYAML(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(result)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// tomlToYAML re-encodes a TOML document so that the yaml tags and strict key
+// checking apply to it as well.
+func tomlToYAML(data []byte) ([]byte, error) {
+	var document map[string]any
+
+	_, err := toml.NewDecoder(bytes.NewReader(data)).Decode(&document)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	converted, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert toml: %w", err)
+	}
+
+	return converted, nil
+}
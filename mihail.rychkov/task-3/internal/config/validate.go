@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/compress"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/Rychmick/task-3/internal/source"
+)
+
+const currencyCodeLength = 3
+
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ValidationError lists every problem found at once, each prefixed by the setting it concerns.
+type ValidationError struct {
+	Problems []string
+}
+
+func (obj *ValidationError) Error() string {
+	return ErrInvalidConfig.Error() + ":\n  - " + strings.Join(obj.Problems, "\n  - ")
+}
+
+func (obj *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+type checker struct {
+	problems []string
+}
+
+func (obj *checker) add(key string, format string, args ...any) {
+	obj.problems = append(obj.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (obj *checker) check(key string, err error) {
+	if err != nil {
+		obj.add(key, "%v", err)
+	}
+}
+
+func (obj *checker) nonNegative(key string, value int64) {
+	if value < 0 {
+		obj.add(key, "must not be negative, got %d", value)
+	}
+}
+
+func (obj *checker) err() error {
+	if len(obj.problems) == 0 {
+		return nil
+	}
+
+	return &ValidationError{obj.problems}
+}
+
+func (obj *Settings) Validate() error {
+	var result checker
+
+	if obj.OutputFormat != "" {
+		_, err := output.Lookup(obj.OutputFormat)
+		result.check("output-format", err)
+	}
+
+	if obj.InputFormat != "" && !strings.EqualFold(obj.InputFormat, source.AutoFormat) {
+		_, err := source.Lookup(obj.InputFormat)
+		result.check("input-format", err)
+	}
+
+	if obj.Precision != nil {
+		result.nonNegative("precision", int64(*obj.Precision))
+	}
+
+	if obj.BaseCurrency != "" && len(obj.BaseCurrency) != currencyCodeLength {
+		result.add("base-currency", "expected a three-letter code, got %q", obj.BaseCurrency)
+	}
+
+	_, err := currency.ParseMode(string(obj.Parsing.Mode))
+	result.check("parsing.mode", err)
+	result.nonNegative("parsing.error-threshold", int64(obj.Parsing.Threshold))
+
+	obj.validateFetch(&result)
+	obj.validateAlerts(&result)
+
+	result.nonNegative("watch.interval", int64(obj.Watch.Interval))
+	result.nonNegative("watch.debounce", int64(obj.Watch.Debounce))
+	result.nonNegative("batch.workers", int64(obj.Batch.Workers))
+	result.nonNegative("server.refresh", int64(obj.Server.Refresh))
+	result.check("selection", obj.Selection.Validate())
+
+	return result.err()
+}
+
+func httpURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func (obj *Settings) validateFetch(result *checker) {
+	fetch := &obj.Fetch
+
+	if fetch.BaseURL != "" && !httpURL(fetch.BaseURL) {
+		result.add("cbr.base-url", "expected an http(s) URL, got %q", fetch.BaseURL)
+	}
+
+	if fetch.Date != "" {
+		_, err := time.Parse(time.DateOnly, fetch.Date)
+		if err != nil {
+			result.add("cbr.date", "expected YYYY-MM-DD, got %q", fetch.Date)
+		}
+	}
+
+	result.nonNegative("cbr.timeout", int64(fetch.Timeout))
+	result.nonNegative("cbr.retries", int64(fetch.Retries))
+	result.nonNegative("cbr.retry-delay", int64(fetch.RetryDelay))
+}
+
+func (obj *Settings) validateAlerts(result *checker) {
+	alerts := &obj.Alerts
+
+	if alerts.Webhook != "" && !httpURL(alerts.Webhook) {
+		result.add("alerts.webhook", "expected an http(s) URL, got %q", alerts.Webhook)
+	}
+
+	result.nonNegative("alerts.webhook-timeout", int64(alerts.WebhookTimeout))
+
+	if len(alerts.Rules) == 0 {
+		return
+	}
+
+	if alerts.File == "" && !alerts.Stderr && alerts.Webhook == "" && !alerts.ExitCode {
+		result.add("alerts", "rules have no action, set file, stderr, webhook or exit-code")
+	}
+
+	if obj.Streaming {
+		result.add("alerts.rules", "not evaluated in streaming mode")
+	}
+}
+
+// Require checks the input and/or output settings of the command about to run.
+func (obj *Settings) Require(input bool, output bool) error {
+	var result checker
+
+	switch {
+	case !input, obj.Fetch.Enabled:
+	case obj.InputFilePath == "":
+		result.add("input-file", "required unless cbr.enabled is set (env %s)", EnvName("input-file"))
+	default:
+		archive, _ := compress.Split(obj.InputFilePath)
+
+		info, err := os.Stat(archive)
+
+		switch {
+		case err != nil:
+			result.add("input-file", "cannot use %q: %v", obj.InputFilePath, errors.Unwrap(err))
+		case info.IsDir():
+			result.add("input-file", "%q is a directory", obj.InputFilePath)
+		}
+	}
+
+	if output && obj.OutputFilePath == "" {
+		result.add("output-file", "required (env %s)", EnvName("output-file"))
+	}
+
+	return result.err()
+}
@@ -0,0 +1,148 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+)
+
+const (
+	BaseCode    = "RUB"
+	baseNumCode = 643
+	baseName    = "Российский рубль"
+
+	// rebasePlaces keeps rebased rates exact enough for any output precision;
+	// they are only rounded when written.
+	rebasePlaces = 10
+)
+
+var ErrUnknownCurrency = errors.New("currency is not in the snapshot")
+
+// Converter holds the price of one unit of every currency in the snapshot base (RUB for CBR feeds).
+type Converter struct {
+	base  string
+	rates map[string]decimal.Decimal
+	items map[string]currency.Currency
+}
+
+func New(rates currency.Rates) (*Converter, error) {
+	base := rates.Base
+	if base == "" {
+		base = BaseCode
+	}
+
+	result := &Converter{
+		base:  base,
+		rates: map[string]decimal.Decimal{base: decimal.FromInt(1)},
+		items: make(map[string]currency.Currency, len(rates.Data)),
+	}
+
+	for _, item := range rates.Data {
+		rate, err := item.UnitRate()
+		if err != nil {
+			return nil, fmt.Errorf("cannot use %s for conversion: %w", item.CharCode, err)
+		}
+
+		code := strings.ToUpper(item.CharCode)
+		result.rates[code] = rate
+		result.items[code] = item
+	}
+
+	return result, nil
+}
+
+func (obj *Converter) unitRate(code string) (decimal.Decimal, error) {
+	rate, exists := obj.rates[strings.ToUpper(code)]
+	if !exists {
+		return decimal.Decimal{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+
+	return rate, nil
+}
+
+// Rate returns how many units of "to" one unit of "from" is worth.
+func (obj *Converter) Rate(from, to string, places int32) (decimal.Decimal, error) {
+	return obj.Convert(decimal.FromInt(1), from, to, places)
+}
+
+func (obj *Converter) Convert(amount decimal.Decimal, from, to string, places int32) (decimal.Decimal, error) {
+	fromRate, err := obj.unitRate(from)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	toRate, err := obj.unitRate(to)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	result, err := amount.Mul(fromRate).Div(toRate, places)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("cannot convert %s to %s: %w", from, to, err)
+	}
+
+	return result, nil
+}
+
+// Rebase re-expresses the whole table in another currency; the former base
+// currency is added as a regular entry and the new base is left out. Values
+// carry up to ten fractional digits, callers round them for output.
+func (obj *Converter) Rebase(base string) (currency.Rates, error) {
+	base = strings.ToUpper(base)
+
+	var result currency.Rates
+
+	result.Base = base
+
+	if _, err := obj.unitRate(base); err != nil {
+		return result, err
+	}
+
+	items := make([]currency.Currency, 0, len(obj.rates))
+
+	for code := range obj.rates {
+		if code == base {
+			continue
+		}
+
+		item, exists := obj.items[code]
+		if !exists {
+			item = currency.Currency{
+				ID: "", NumCode: 0, CharCode: code, Nominal: 1, Name: "",
+				Value: decimal.Decimal{}, VunitRate: decimal.Decimal{}, Rate: decimal.Decimal{},
+			}
+
+			if code == BaseCode {
+				item.NumCode = baseNumCode
+				item.Name = baseName
+			}
+		}
+
+		rate, err := obj.Rate(code, base, rebasePlaces)
+		if err != nil {
+			return result, err
+		}
+
+		value, err := obj.Convert(decimal.FromInt(int64(item.Nominal)), code, base, rebasePlaces)
+		if err != nil {
+			return result, err
+		}
+
+		item.Rate = rate.TrimZeros(0)
+		item.VunitRate = item.Rate
+		item.Value = value.TrimZeros(0)
+		items = append(items, item)
+	}
+
+	slices.SortFunc(items, func(lhs, rhs currency.Currency) int {
+		return strings.Compare(lhs.CharCode, rhs.CharCode)
+	})
+
+	result.Data = items
+
+	return result, nil
+}
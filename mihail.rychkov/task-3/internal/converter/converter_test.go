@@ -0,0 +1,83 @@
+package converter_test
+
+import (
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/converter"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func item(code string, nominal uint, value string) currency.Currency {
+	return currency.Currency{
+		ID: "", NumCode: 0, CharCode: code, Nominal: nominal, Name: code,
+		Value: decimal.MustParse(value), VunitRate: decimal.Decimal{}, Rate: decimal.Decimal{},
+	}
+}
+
+func snapshot() currency.Rates {
+	var rates currency.Rates
+
+	rates.Data = []currency.Currency{item("USD", 1, "90,0000"), item("EUR", 1, "99,0000"), item("JPY", 100, "60,0000")}
+
+	return rates
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	conv, err := converter.New(snapshot())
+	require.NoError(t, err)
+
+	result, err := conv.Convert(decimal.FromInt(100), "USD", "EUR", 4)
+	require.NoError(t, err)
+	assert.Equal(t, "90.9091", result.String())
+
+	result, err = conv.Convert(decimal.FromInt(1000), "jpy", "RUB", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "600.00", result.String())
+
+	result, err = conv.Rate("USD", "JPY", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "150.00", result.String())
+
+	_, err = conv.Convert(decimal.FromInt(1), "USD", "XXX", 2)
+	require.ErrorIs(t, err, converter.ErrUnknownCurrency)
+}
+
+func TestRebase(t *testing.T) {
+	t.Parallel()
+
+	conv, err := converter.New(snapshot())
+	require.NoError(t, err)
+
+	rebased, err := conv.Rebase("usd")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", rebased.Base)
+
+	codes := make([]string, 0, len(rebased.Data))
+	for _, entry := range rebased.Data {
+		codes = append(codes, entry.CharCode)
+	}
+
+	assert.Equal(t, []string{"EUR", "JPY", "RUB"}, codes)
+
+	jpy, found := rebased.Find("JPY")
+	require.True(t, found)
+	assert.Equal(t, "0.0066666667", jpy.Rate.String())
+	assert.Equal(t, "0.6666666667", jpy.Value.String())
+
+	jpy.Round(4)
+	assert.Equal(t, "0.0067", jpy.Rate.String())
+
+	rub, found := rebased.Find("RUB")
+	require.True(t, found)
+	assert.Equal(t, uint(643), rub.NumCode)
+	assert.Equal(t, "0.0111111111", rub.Rate.String())
+
+	eur, found := rebased.Find("EUR")
+	require.True(t, found)
+	assert.Equal(t, "1.1", eur.Rate.String())
+}
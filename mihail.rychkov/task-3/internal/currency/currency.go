@@ -2,39 +2,177 @@ package currency
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"strconv"
 	"strings"
-)
+	"time"
 
-type (
-	ValueWrapper float32
-	Currency     struct {
-		NumCode  uint         `json:"num_code"  xml:"NumCode"`
-		CharCode string       `json:"char_code" xml:"CharCode"`
-		Value    ValueWrapper `json:"value"     xml:"Value"`
-	}
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/iso4217"
+	"github.com/Rychmick/task-3/internal/output"
 )
 
+const unitRatePlaces = 10
+
+type Currency struct {
+	ID        string          `json:"id"             xml:"ID,attr"`
+	RecordID  string          `json:"-"              xml:"Id,attr"`
+	Date      string          `json:"date,omitempty" xml:"Date,attr"`
+	NumCode   uint            `json:"num_code"       xml:"NumCode"`
+	CharCode  string          `json:"char_code"      xml:"CharCode"`
+	Nominal   uint            `json:"nominal"        xml:"Nominal"`
+	Name      string          `json:"name"           xml:"Name"`
+	Value     decimal.Decimal `json:"value"          xml:"Value"`
+	VunitRate decimal.Decimal `json:"vunit_rate"     xml:"VunitRate"`
+	Rate      decimal.Decimal `json:"rate"           xml:"-"`
+
+	// ISO is only set when records are enriched from the ISO 4217 table.
+	ISO *iso4217.Currency `json:"-" xml:"-"`
+}
+
 type Rates struct {
-	XMLName xml.Name   `xml:"ValCurs"`
-	Data    []Currency `xml:"Valute"`
+	XMLName xml.Name   `json:"-"    xml:"ValCurs"`
+	Date    string     `json:"date" xml:"Date,attr"`
+	Name    string     `json:"name" xml:"name,attr"`
+	Base    string     `json:"base" xml:"-"`
+	Data    []Currency `json:"data" xml:"Valute"`
 }
 
-func (wrapper *ValueWrapper) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
-	var raw string
+const dateLayout = "02.01.2006"
+
+var (
+	ErrZeroNominal = errors.New("currency nominal is zero")
+	ErrNoDate      = errors.New("rates snapshot has no date")
+)
+
+func (obj *Rates) Day() (time.Time, error) {
+	if obj.Date == "" {
+		return time.Time{}, ErrNoDate
+	}
 
-	err := decoder.DecodeElement(&raw, &start)
+	day, err := time.Parse(dateLayout, obj.Date)
 	if err != nil {
-		return fmt.Errorf("failed to parse raw Valute: %w", err)
+		return time.Time{}, fmt.Errorf("invalid snapshot date: %w", err)
+	}
+
+	return day, nil
+}
+
+func (obj *Rates) Find(charCode string) (Currency, bool) {
+	for _, item := range obj.Data {
+		if strings.EqualFold(item.CharCode, charCode) {
+			return item, true
+		}
+	}
+
+	return Currency{}, false
+}
+
+// UnitRate prefers the published VunitRate and falls back to Value/Nominal for feeds without it.
+func (obj *Currency) UnitRate() (decimal.Decimal, error) {
+	if !obj.VunitRate.IsZero() {
+		return obj.VunitRate, nil
+	}
+
+	if obj.Nominal == 0 {
+		return decimal.Decimal{}, fmt.Errorf("%w for %s", ErrZeroNominal, obj.CharCode)
+	}
+
+	rate, err := obj.Value.Div(decimal.FromInt(int64(obj.Nominal)), unitRatePlaces)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("cannot compute unit rate for %s: %w", obj.CharCode, err)
+	}
+
+	return rate.TrimZeros(obj.Value.Scale()), nil
+}
+
+// Normalize fills the computed per-unit rate; dynamic feed records carry their ID in the "Id" attribute.
+func (obj *Currency) Normalize() error {
+	if obj.ID == "" {
+		obj.ID = obj.RecordID
 	}
 
-	value, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 32)
+	rate, err := obj.UnitRate()
 	if err != nil {
-		return fmt.Errorf("failed to parse rate value: %w", err)
+		return err
 	}
 
-	*wrapper = ValueWrapper(value)
+	obj.Rate = rate
 
 	return nil
 }
+
+func (obj *Rates) Normalize() error {
+	for idx := range obj.Data {
+		err := obj.Data[idx].Normalize()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (obj *Currency) Round(places int32) {
+	obj.Value = obj.Value.Round(places)
+	obj.VunitRate = obj.VunitRate.Round(places)
+	obj.Rate = obj.Rate.Round(places)
+}
+
+func (obj *Rates) Round(places int32) {
+	for idx := range obj.Data {
+		obj.Data[idx].Round(places)
+	}
+}
+
+func (obj *Currency) Record() output.Record {
+	var record output.Record
+
+	if obj.Date != "" {
+		record = append(record, output.Field{Name: "date", Value: obj.Date})
+	}
+
+	record = append(record, output.Record{
+		{Name: "id", Value: obj.ID},
+		{Name: "num_code", Value: obj.NumCode},
+		{Name: "char_code", Value: obj.CharCode},
+		{Name: "nominal", Value: obj.Nominal},
+		{Name: "name", Value: obj.Name},
+		{Name: "value", Value: obj.Value},
+		{Name: "vunit_rate", Value: obj.VunitRate},
+		{Name: "rate", Value: obj.Rate},
+	}...)
+
+	if obj.ISO != nil {
+		var minorUnits any
+		if obj.ISO.MinorUnits != iso4217.NoMinorUnits {
+			minorUnits = obj.ISO.MinorUnits
+		}
+
+		record = append(record,
+			output.Field{Name: "english_name", Value: obj.ISO.Name},
+			output.Field{Name: "minor_units", Value: minorUnits})
+	}
+
+	return record
+}
+
+// Columns lists the fields of a record without a per-record date, for writing
+// the header of an empty table.
+func Columns(enriched bool) []string {
+	var item Currency
+	if enriched {
+		item.ISO = new(iso4217.Currency)
+	}
+
+	return item.Record().Names()
+}
+
+func (obj *Rates) Records() []output.Record {
+	records := make([]output.Record, len(obj.Data))
+	for idx := range obj.Data {
+		records[idx] = obj.Data[idx].Record()
+	}
+
+	return records
+}
@@ -0,0 +1,98 @@
+package currency_test
+
+import (
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		currency currency.Currency
+		expected string
+		err      error
+	}{
+		{
+			name: "prefers vunit rate",
+			currency: currency.Currency{
+				CharCode: "JPY", Nominal: 100,
+				Value: decimal.MustParse("60.5"), VunitRate: decimal.MustParse("0.6049"),
+			},
+			expected: "0.6049",
+		},
+		{
+			name: "divides value by nominal",
+			currency: currency.Currency{
+				CharCode: "JPY", Nominal: 100, Value: decimal.MustParse("60.5"),
+			},
+			expected: "0.605",
+		},
+		{
+			name: "keeps the scale of value",
+			currency: currency.Currency{
+				CharCode: "USD", Nominal: 1, Value: decimal.MustParse("91.20"),
+			},
+			expected: "91.20",
+		},
+		{
+			name: "vunit rate ignores zero nominal",
+			currency: currency.Currency{
+				CharCode: "CNY", Nominal: 0,
+				Value: decimal.MustParse("12.5"), VunitRate: decimal.MustParse("12.5"),
+			},
+			expected: "12.5",
+		},
+		{
+			name: "zero nominal",
+			currency: currency.Currency{
+				CharCode: "CNY", Nominal: 0, Value: decimal.MustParse("12.5"),
+			},
+			err: currency.ErrZeroNominal,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rate, err := test.currency.UnitRate()
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				assert.ErrorContains(t, err, test.currency.CharCode)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, rate.String())
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	rates := currency.Rates{Data: []currency.Currency{
+		{RecordID: "R01235", CharCode: "USD", Nominal: 1, Value: decimal.MustParse("91.2")},
+		{ID: "R01239", RecordID: "other", CharCode: "EUR", Nominal: 10, Value: decimal.MustParse("985")},
+	}}
+
+	require.NoError(t, rates.Normalize())
+	assert.Equal(t, "R01235", rates.Data[0].ID)
+	assert.Equal(t, "91.2", rates.Data[0].Rate.String())
+	assert.Equal(t, "R01239", rates.Data[1].ID)
+	assert.Equal(t, "98.5", rates.Data[1].Rate.String())
+
+	rates.Data = append(rates.Data, currency.Currency{CharCode: "CNY", Value: decimal.MustParse("12.5")})
+
+	err := rates.Normalize()
+	require.ErrorIs(t, err, currency.ErrZeroNominal)
+	assert.ErrorContains(t, err, "CNY")
+	assert.True(t, rates.Data[2].Rate.IsZero())
+}
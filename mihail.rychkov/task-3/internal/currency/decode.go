@@ -0,0 +1,305 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/Rychmick/task-3/internal/xml"
+)
+
+type Mode string
+
+const (
+	ModeStrict  Mode = "strict"
+	ModeSkip    Mode = "skip"
+	ModeDefault Mode = "default"
+)
+
+var ErrUnknownMode = errors.New("unknown parse mode")
+
+func ParseMode(raw string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(raw)); mode {
+	case "":
+		return ModeStrict, nil
+	case ModeStrict, ModeSkip, ModeDefault:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownMode, raw)
+	}
+}
+
+type Problem struct {
+	Position xml.Position
+	CharCode string
+	Field    string
+	Message  string
+}
+
+func (obj *Problem) String() string {
+	if obj.Position.Line == 0 {
+		return fmt.Sprintf("%s #%d (%s): %s: %s", obj.Position.Element, obj.Position.Index,
+			obj.CharCode, obj.Field, obj.Message)
+	}
+
+	return fmt.Sprintf("%s #%d (%s) at line %d, column %d: %s: %s", obj.Position.Element, obj.Position.Index,
+		obj.CharCode, obj.Position.Line, obj.Position.Column, obj.Field, obj.Message)
+}
+
+func (obj *Problem) Record() output.Record {
+	return output.Record{
+		{Name: "index", Value: obj.Position.Index},
+		{Name: "element", Value: obj.Position.Element},
+		{Name: "char_code", Value: obj.CharCode},
+		{Name: "line", Value: obj.Position.Line},
+		{Name: "column", Value: obj.Position.Column},
+		{Name: "field", Value: obj.Field},
+		{Name: "message", Value: obj.Message},
+	}
+}
+
+func ProblemColumns() []string {
+	var problem Problem
+
+	return problem.Record().Names()
+}
+
+type ParseError struct {
+	Problem Problem
+}
+
+func (obj *ParseError) Error() string {
+	return "invalid currency entry: " + obj.Problem.String()
+}
+
+// RawCurrency mirrors Currency with every element kept as text, so a bad value
+// is reported with its location instead of aborting the whole document.
+// Parsers of other sources fill it too and share the same validation.
+type RawCurrency struct {
+	ID        string `xml:"ID,attr"`
+	RecordID  string `xml:"Id,attr"`
+	Date      string `xml:"Date,attr"`
+	NumCode   string `xml:"NumCode"`
+	CharCode  string `xml:"CharCode"`
+	Nominal   string `xml:"Nominal"`
+	Name      string `xml:"Name"`
+	Value     string `xml:"Value"`
+	VunitRate string `xml:"VunitRate"`
+}
+
+type fieldError struct {
+	field string
+	err   error
+}
+
+func parseUint(raw string, field string, required bool, fallback uint, problems *[]fieldError) uint {
+	raw = strings.TrimSpace(raw)
+	if raw == "" && !required {
+		return 0
+	}
+
+	value, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		*problems = append(*problems, fieldError{field, err})
+
+		return fallback
+	}
+
+	return uint(value)
+}
+
+func parseDecimal(raw string, field string, required bool, problems *[]fieldError) decimal.Decimal {
+	if strings.TrimSpace(raw) == "" && !required {
+		return decimal.Decimal{}
+	}
+
+	value, err := decimal.Parse(raw)
+	if err != nil {
+		*problems = append(*problems, fieldError{field, err})
+	}
+
+	return value
+}
+
+func (obj *RawCurrency) convert() (Currency, []fieldError) {
+	var problems []fieldError
+
+	result := Currency{
+		ID:        strings.TrimSpace(obj.ID),
+		RecordID:  strings.TrimSpace(obj.RecordID),
+		Date:      strings.TrimSpace(obj.Date),
+		NumCode:   parseUint(obj.NumCode, "NumCode", false, 0, &problems),
+		CharCode:  strings.TrimSpace(obj.CharCode),
+		Nominal:   parseUint(obj.Nominal, "Nominal", true, 1, &problems),
+		Name:      strings.TrimSpace(obj.Name),
+		Value:     parseDecimal(obj.Value, "Value", true, &problems),
+		VunitRate: parseDecimal(obj.VunitRate, "VunitRate", false, &problems),
+		Rate:      decimal.Decimal{},
+	}
+
+	if result.Nominal == 0 {
+		problems = append(problems, fieldError{"Nominal", ErrZeroNominal})
+		result.Nominal = 1
+	}
+
+	return result, problems
+}
+
+// Collector validates raw entries and remembers the problems according to the mode.
+type Collector struct {
+	mode     Mode
+	problems []Problem
+	iso      *isoCheck
+}
+
+func NewCollector(mode Mode) *Collector {
+	return &Collector{mode, nil, nil}
+}
+
+func (obj *Collector) Problems() []Problem {
+	return obj.problems
+}
+
+// Accept converts and normalizes a raw entry. In strict mode the first bad
+// entry fails with a *ParseError; otherwise bad entries are recorded and either
+// skipped (keep is false) or kept with defaulted fields.
+func (obj *Collector) Accept(position xml.Position, raw *RawCurrency) (Currency, bool, error) {
+	item, problems := raw.convert()
+	if len(problems) == 0 {
+		err := item.Normalize()
+		if err != nil {
+			problems = append(problems, fieldError{"Rate", err})
+		}
+	}
+
+	if len(problems) == 0 && obj.iso != nil {
+		problems = obj.iso.check(position.Index, &item)
+	}
+
+	if len(problems) == 0 {
+		return item, true, nil
+	}
+
+	for _, problem := range problems {
+		obj.problems = append(obj.problems, Problem{position, item.CharCode, problem.field, problem.err.Error()})
+	}
+
+	switch obj.mode {
+	case ModeStrict:
+		return item, false, &ParseError{obj.problems[len(obj.problems)-1]}
+	case ModeDefault:
+		_ = item.Normalize()
+
+		return item, true, nil
+	default:
+		return item, false, nil
+	}
+}
+
+// Reject records a problem found by the source parser itself, such as a rate
+// that cannot be inverted. It returns a *ParseError in strict mode.
+func (obj *Collector) Reject(position xml.Position, charCode string, field string, err error) error {
+	obj.problems = append(obj.problems, Problem{position, charCode, field, err.Error()})
+
+	if obj.mode == ModeStrict {
+		return &ParseError{obj.problems[len(obj.problems)-1]}
+	}
+
+	return nil
+}
+
+type Decoder struct {
+	stream    *xml.Stream[RawCurrency]
+	collector *Collector
+}
+
+func NewDecoder(reader io.Reader, collector *Collector) *Decoder {
+	return &Decoder{xml.NewStream[RawCurrency](reader, "Valute", "Record"), collector}
+}
+
+func (obj *Decoder) Problems() []Problem {
+	return obj.collector.Problems()
+}
+
+// Root returns the ValCurs attributes seen so far.
+func (obj *Decoder) Root() (string, string) {
+	root, found := obj.stream.Root()
+	if !found {
+		return "", ""
+	}
+
+	var date, name string
+
+	for _, attr := range root.Attr {
+		switch attr.Name.Local {
+		case "Date":
+			date = attr.Value
+		case "name":
+			name = attr.Value
+		}
+	}
+
+	return date, name
+}
+
+// Next returns the following normalized currency, see Collector.Accept for
+// how bad entries are treated.
+func (obj *Decoder) Next(result *Currency) error {
+	for {
+		var raw RawCurrency
+
+		err := obj.stream.Next(&raw)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		item, keep, err := obj.collector.Accept(obj.stream.Position(), &raw)
+		if err != nil {
+			return err
+		}
+
+		if keep {
+			*result = item
+
+			return nil
+		}
+	}
+}
+
+func Decode(reader io.Reader, mode Mode) (Rates, []Problem, error) {
+	collector := NewCollector(mode)
+
+	result, err := DecodeWith(reader, collector)
+
+	return result, collector.Problems(), err
+}
+
+// DecodeWith reads a ValCurs document, reporting bad entries to the collector.
+func DecodeWith(reader io.Reader, collector *Collector) (Rates, error) {
+	var result Rates
+
+	decoder := NewDecoder(reader, collector)
+
+	for {
+		var item Currency
+
+		err := decoder.Next(&item)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return result, err
+		}
+
+		result.Data = append(result.Data, item)
+	}
+
+	result.Date, result.Name = decoder.Root()
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package currency_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const broken = `<?xml version="1.0" encoding="UTF-8"?>
+<ValCurs Date="02.03.2024" name="Foreign Currency Market">
+  <Valute ID="R01235">
+    <NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal>
+    <Name>Доллар США</Name><Value>91,2</Value>
+  </Valute>
+  <Valute ID="R01239">
+    <NumCode>978</NumCode><CharCode>EUR</CharCode><Nominal>1</Nominal>
+    <Name>Евро</Name><Value>n/a</Value>
+  </Valute>
+  <Valute ID="R01375">
+    <NumCode>156</NumCode><CharCode>CNY</CharCode><Nominal>0</Nominal>
+    <Name>Юань</Name><Value>12,5</Value>
+  </Valute>
+</ValCurs>`
+
+func TestDecodeStrict(t *testing.T) {
+	t.Parallel()
+
+	_, problems, err := currency.Decode(strings.NewReader(broken), currency.ModeStrict)
+
+	var parseErr *currency.ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	require.Len(t, problems, 1)
+	assert.Equal(t, 1, parseErr.Problem.Position.Index)
+	assert.Equal(t, 7, parseErr.Problem.Position.Line)
+	assert.Equal(t, "EUR", parseErr.Problem.CharCode)
+	assert.Equal(t, "Value", parseErr.Problem.Field)
+}
+
+func TestDecodeLenient(t *testing.T) {
+	t.Parallel()
+
+	rates, problems, err := currency.Decode(strings.NewReader(broken), currency.ModeSkip)
+	require.NoError(t, err)
+	require.Len(t, rates.Data, 1)
+	assert.Equal(t, "USD", rates.Data[0].CharCode)
+	assert.Equal(t, "02.03.2024", rates.Date)
+	require.Len(t, problems, 2)
+	assert.Equal(t, "CNY", problems[1].CharCode)
+	assert.Equal(t, 11, problems[1].Position.Line)
+
+	rates, problems, err = currency.Decode(strings.NewReader(broken), currency.ModeDefault)
+	require.NoError(t, err)
+	require.Len(t, rates.Data, 3)
+	assert.True(t, rates.Data[1].Value.IsZero())
+	assert.Equal(t, uint(1), rates.Data[2].Nominal)
+	assert.Equal(t, "12.5", rates.Data[2].Rate.String())
+	assert.Len(t, problems, 2)
+}
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+
+	mode, err := currency.ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, currency.ModeStrict, mode)
+
+	_, err = currency.ParseMode("sloppy")
+	require.ErrorIs(t, err, currency.ErrUnknownMode)
+}
+
+const unchecked = `<?xml version="1.0" encoding="UTF-8"?>
+<ValCurs Date="02.03.2024" name="Foreign Currency Market">
+  <Valute ID="R01235">
+    <NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal>
+    <Name>Доллар США</Name><Value>91,2</Value>
+  </Valute>
+  <Valute ID="R01239">
+    <NumCode>987</NumCode><CharCode>EUR</CharCode><Nominal>1</Nominal>
+    <Name>Евро</Name><Value>99,1</Value>
+  </Valute>
+  <Valute ID="R01589">
+    <NumCode>960</NumCode><CharCode>XDR</CharCode><Nominal>1</Nominal>
+    <Name>СДР</Name><Value>121,3</Value>
+  </Valute>
+  <Valute ID="R09999">
+    <NumCode>999</NumCode><CharCode>ZZZ</CharCode><Nominal>1</Nominal>
+    <Name>Нет такой</Name><Value>1</Value>
+  </Valute>
+  <Valute ID="R01235">
+    <NumCode>840</NumCode><CharCode>usd</CharCode><Nominal>1</Nominal>
+    <Name>Доллар США</Name><Value>91,3</Value>
+  </Valute>
+</ValCurs>`
+
+func TestCheckISO(t *testing.T) {
+	t.Parallel()
+
+	collector := currency.NewCollector(currency.ModeSkip).CheckISO(true)
+
+	rates, err := currency.DecodeWith(strings.NewReader(unchecked), collector)
+	require.NoError(t, err)
+	require.Len(t, rates.Data, 2)
+
+	problems := collector.Problems()
+	require.Len(t, problems, 3)
+	assert.Equal(t, "NumCode", problems[0].Field)
+	assert.Contains(t, problems[0].Message, "EUR is 978, got 987")
+	assert.Equal(t, "ZZZ", problems[1].CharCode)
+	assert.Contains(t, problems[2].Message, "first seen at #0")
+
+	record := rates.Data[0].Record()
+	assert.Equal(t, "english_name", record[len(record)-2].Name)
+	assert.Equal(t, "US Dollar", record[len(record)-2].Value)
+	assert.Equal(t, 2, record[len(record)-1].Value)
+
+	record = rates.Data[1].Record()
+	assert.Nil(t, record[len(record)-1].Value, "XDR has no minor units")
+
+	_, err = currency.DecodeWith(strings.NewReader(unchecked), currency.NewCollector(currency.ModeStrict).CheckISO(false))
+
+	var parseErr *currency.ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "EUR", parseErr.Problem.CharCode)
+}
@@ -0,0 +1,62 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/iso4217"
+)
+
+var (
+	ErrUnknownCode   = errors.New("not an ISO 4217 currency code")
+	ErrCodeMismatch  = errors.New("numeric and alphabetic codes disagree")
+	ErrDuplicateCode = errors.New("currency is listed more than once")
+)
+
+// isoCheck validates entries against ISO 4217 and remembers the codes seen so
+// far to catch duplicates. Dynamic records without codes are left alone.
+type isoCheck struct {
+	enrich bool
+	seen   map[string]int
+}
+
+func (obj *isoCheck) check(index int, item *Currency) []fieldError {
+	if item.CharCode == "" {
+		return nil
+	}
+
+	var problems []fieldError
+
+	code := strings.ToUpper(item.CharCode)
+
+	if first, exists := obj.seen[code]; exists {
+		problems = append(problems, fieldError{"CharCode", fmt.Errorf("%w: first seen at #%d", ErrDuplicateCode, first)})
+	} else {
+		obj.seen[code] = index
+	}
+
+	entry, found := iso4217.Lookup(code)
+
+	switch {
+	case !found:
+		problems = append(problems, fieldError{"CharCode", fmt.Errorf("%w: %q", ErrUnknownCode, item.CharCode)})
+	case item.NumCode != 0 && item.NumCode != entry.Number:
+		problems = append(problems, fieldError{"NumCode", fmt.Errorf("%w: %s is %03d, got %03d",
+			ErrCodeMismatch, entry.Code, entry.Number, item.NumCode)})
+	}
+
+	if found && obj.enrich {
+		item.ISO = &entry
+	}
+
+	return problems
+}
+
+// CheckISO makes the collector validate codes against ISO 4217 and report
+// duplicates; with enrich, known currencies get their ISO details attached.
+func (obj *Collector) CheckISO(enrich bool) *Collector {
+	obj.iso = &isoCheck{enrich, map[string]int{}}
+
+	return obj
+}
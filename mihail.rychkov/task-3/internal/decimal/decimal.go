@@ -0,0 +1,268 @@
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const decimalBase = 10
+
+var (
+	ErrInvalidDecimal = errors.New("invalid decimal number")
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+// Decimal is an exact base-10 number: coef * 10^-scale. The zero value is 0.
+// The coefficient is never mutated once a Decimal is built, so values can be copied freely.
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+// pow10 only takes non-negative exponents; ratPow10 handles negative ones.
+func pow10(exp int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(decimalBase), big.NewInt(int64(exp)), nil)
+}
+
+func ratPow10(exp int32) *big.Rat {
+	if exp < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow10(-exp))
+	}
+
+	return new(big.Rat).SetInt(pow10(exp))
+}
+
+func (obj Decimal) coefficient() *big.Int {
+	if obj.coef == nil {
+		return new(big.Int)
+	}
+
+	return obj.coef
+}
+
+func New(coef int64, scale int32) Decimal {
+	return Decimal{big.NewInt(coef), scale}
+}
+
+func FromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// Parse accepts both "92.5730" and the CBR style "92,5730".
+func Parse(raw string) (Decimal, error) {
+	str := strings.TrimSpace(raw)
+
+	digits := strings.TrimLeft(str, "+-")
+	if len(str)-len(digits) > 1 || digits == "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, raw)
+	}
+
+	intPart, fracPart, hasPoint := strings.Cut(strings.Replace(digits, ",", ".", 1), ".")
+	if (intPart == "" && fracPart == "") || (hasPoint && fracPart == "") {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, raw)
+	}
+
+	for _, char := range intPart + fracPart {
+		if char < '0' || char > '9' {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, raw)
+		}
+	}
+
+	coef, ok := new(big.Int).SetString(intPart+fracPart, decimalBase)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, raw)
+	}
+
+	if strings.HasPrefix(str, "-") {
+		coef.Neg(coef)
+	}
+
+	return Decimal{coef, int32(len(fracPart))}, nil //nolint:gosec
+}
+
+func MustParse(raw string) Decimal {
+	result, err := Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+func (obj Decimal) Scale() int32 {
+	return obj.scale
+}
+
+func (obj Decimal) Sign() int {
+	return obj.coefficient().Sign()
+}
+
+func (obj Decimal) IsZero() bool {
+	return obj.Sign() == 0
+}
+
+// rescale returns the coefficient expressed with a larger scale.
+func (obj Decimal) rescale(scale int32) *big.Int {
+	if scale <= obj.scale {
+		return obj.coefficient()
+	}
+
+	return new(big.Int).Mul(obj.coefficient(), pow10(scale-obj.scale))
+}
+
+func (obj Decimal) Cmp(other Decimal) int {
+	scale := max(obj.scale, other.scale)
+
+	return obj.rescale(scale).Cmp(other.rescale(scale))
+}
+
+func (obj Decimal) Equal(other Decimal) bool {
+	return obj.Cmp(other) == 0
+}
+
+func (obj Decimal) Add(other Decimal) Decimal {
+	scale := max(obj.scale, other.scale)
+
+	return Decimal{new(big.Int).Add(obj.rescale(scale), other.rescale(scale)), scale}
+}
+
+func (obj Decimal) Sub(other Decimal) Decimal {
+	return obj.Add(other.Neg())
+}
+
+func (obj Decimal) Neg() Decimal {
+	return Decimal{new(big.Int).Neg(obj.coefficient()), obj.scale}
+}
+
+func (obj Decimal) Abs() Decimal {
+	if obj.Sign() < 0 {
+		return obj.Neg()
+	}
+
+	return obj
+}
+
+func (obj Decimal) Mul(other Decimal) Decimal {
+	return Decimal{new(big.Int).Mul(obj.coefficient(), other.coefficient()), obj.scale + other.scale}
+}
+
+func roundRat(value *big.Rat, places int32) Decimal {
+	scaled := new(big.Rat).Mul(value, ratPow10(places))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	// round half away from zero: compare 2*|remainder| with the denominator
+	remainder.Abs(remainder).Lsh(remainder, 1)
+	if remainder.Cmp(scaled.Denom()) >= 0 {
+		if scaled.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return Decimal{quotient, places}
+}
+
+func (obj Decimal) rat() *big.Rat {
+	return new(big.Rat).Mul(new(big.Rat).SetInt(obj.coefficient()), ratPow10(-obj.scale))
+}
+
+// Div divides and rounds the result half away from zero to the given number of places.
+func (obj Decimal) Div(other Decimal, places int32) (Decimal, error) {
+	if other.IsZero() {
+		return Decimal{}, ErrDivisionByZero
+	}
+
+	return roundRat(new(big.Rat).Quo(obj.rat(), other.rat()), places), nil
+}
+
+// Round returns the value with exactly the given number of fractional digits;
+// negative places round to tens, hundreds and so on.
+func (obj Decimal) Round(places int32) Decimal {
+	if places >= obj.scale {
+		return Decimal{obj.rescale(places), places}
+	}
+
+	return roundRat(obj.rat(), places)
+}
+
+// TrimZeros drops trailing fractional zeros but keeps at least minScale digits.
+func (obj Decimal) TrimZeros(minScale int32) Decimal {
+	coef := new(big.Int).Set(obj.coefficient())
+	scale := obj.scale
+	ten := big.NewInt(decimalBase)
+	remainder := new(big.Int)
+
+	for scale > minScale {
+		quotient, rem := new(big.Int).QuoRem(coef, ten, remainder)
+		if rem.Sign() != 0 {
+			break
+		}
+
+		coef = quotient
+		scale--
+	}
+
+	return Decimal{coef, scale}
+}
+
+func (obj Decimal) Float64() float64 {
+	result, _ := obj.rat().Float64()
+
+	return result
+}
+
+func (obj Decimal) String() string {
+	digits := new(big.Int).Abs(obj.coefficient()).String()
+
+	var sign string
+	if obj.Sign() < 0 {
+		sign = "-"
+	}
+
+	if obj.scale < 0 && obj.IsZero() {
+		return digits
+	}
+
+	if obj.scale <= 0 {
+		return sign + digits + strings.Repeat("0", int(-obj.scale))
+	}
+
+	if pad := int(obj.scale) - len(digits) + 1; pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+
+	point := len(digits) - int(obj.scale)
+
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (obj Decimal) MarshalText() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+func (obj *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*obj = parsed
+
+	return nil
+}
+
+func (obj Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(obj.String()), nil
+}
+
+// UnmarshalJSON leaves the value unchanged for null, like encoding/json does for numbers.
+func (obj *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	return obj.UnmarshalText([]byte(strings.Trim(string(data), `"`)))
+}
@@ -0,0 +1,94 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for raw, expected := range map[string]string{
+		"92,5730":  "92.5730",
+		"92.5730":  "92.5730",
+		" -0,0042": "-0.0042",
+		"+15":      "15",
+		",5":       "0.5",
+	} {
+		parsed, err := decimal.Parse(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, expected, parsed.String(), raw)
+	}
+
+	for _, raw := range []string{"", "1 234,5", "1,2,3", "--1", "1.", "abc", "-"} {
+		_, err := decimal.Parse(raw)
+		require.ErrorIs(t, err, decimal.ErrInvalidDecimal, raw)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	t.Parallel()
+
+	lhs := decimal.MustParse("61,7580")
+	rhs := decimal.MustParse("0.42")
+
+	assert.Equal(t, "62.1780", lhs.Add(rhs).String())
+	assert.Equal(t, "61.3380", lhs.Sub(rhs).String())
+	assert.Equal(t, "25.938360", lhs.Mul(rhs).String())
+	assert.Equal(t, 1, lhs.Cmp(rhs))
+	assert.True(t, decimal.MustParse("1.50").Equal(decimal.MustParse("1.5")))
+
+	quotient, err := lhs.Div(decimal.FromInt(100), 10)
+	require.NoError(t, err)
+	assert.Equal(t, "0.6175800000", quotient.String())
+	assert.Equal(t, "0.61758", quotient.TrimZeros(4).String())
+
+	third, err := decimal.FromInt(-1).Div(decimal.FromInt(3), 4)
+	require.NoError(t, err)
+	assert.Equal(t, "-0.3333", third.String())
+
+	_, err = lhs.Div(decimal.Decimal{}, 2)
+	require.ErrorIs(t, err, decimal.ErrDivisionByZero)
+}
+
+func TestRound(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "92.57", decimal.MustParse("92,5730").Round(2).String())
+	assert.Equal(t, "0.13", decimal.MustParse("0.125").Round(2).String())
+	assert.Equal(t, "-0.13", decimal.MustParse("-0.125").Round(2).String())
+	assert.Equal(t, "1.5000", decimal.MustParse("1.5").Round(4).String())
+	assert.Equal(t, "90", decimal.MustParse("92,5730").Round(-1).String())
+	assert.Equal(t, "-200", decimal.MustParse("-150").Round(-2).String())
+	assert.Equal(t, "0", decimal.MustParse("4.9").Round(-1).String())
+	assert.Equal(t, "100.0", decimal.MustParse("95").Round(-1).Round(1).String())
+	assert.Equal(t, 1, decimal.MustParse("95").Round(-1).Cmp(decimal.MustParse("99.9")))
+	assert.Equal(t, "0", decimal.Decimal{}.String())
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal([]decimal.Decimal{decimal.MustParse("92,5730")})
+	require.NoError(t, err)
+	assert.Equal(t, "[92.5730]", string(data))
+
+	var parsed []decimal.Decimal
+
+	require.NoError(t, json.Unmarshal([]byte(`[1.25, "3,5"]`), &parsed))
+	assert.Equal(t, "1.25", parsed[0].String())
+	assert.Equal(t, "3.5", parsed[1].String())
+
+	var record struct {
+		Value decimal.Decimal `json:"value"`
+	}
+
+	record.Value = decimal.MustParse("7.5")
+
+	require.NoError(t, json.Unmarshal([]byte(`{"value": null}`), &record))
+	assert.Equal(t, "7.5", record.Value.String())
+}
@@ -0,0 +1,186 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/converter"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/output"
+)
+
+type Status string
+
+const (
+	StatusChanged   Status = "changed"
+	StatusUnchanged Status = "unchanged"
+	StatusAdded     Status = "added"
+	StatusRemoved   Status = "removed"
+
+	percentScale = 100
+)
+
+var ErrBaseMismatch = errors.New("snapshots are quoted in different base currencies")
+
+// Change compares the per-unit rate of one currency in two snapshots.
+// Delta and Percent are only meaningful when the currency is in both.
+type Change struct {
+	CharCode string
+	ID       string
+	Name     string
+	Status   Status
+	Old      decimal.Decimal
+	New      decimal.Decimal
+	Delta    decimal.Decimal
+	Percent  decimal.Decimal
+}
+
+func (obj *Change) Record() output.Record {
+	var oldRate, newRate, delta, percent any
+
+	if obj.Status != StatusAdded {
+		oldRate = obj.Old
+	}
+
+	if obj.Status != StatusRemoved {
+		newRate = obj.New
+	}
+
+	if obj.Status == StatusChanged || obj.Status == StatusUnchanged {
+		delta, percent = obj.Delta, obj.Percent
+	}
+
+	return output.Record{
+		{Name: "char_code", Value: obj.CharCode},
+		{Name: "id", Value: obj.ID},
+		{Name: "name", Value: obj.Name},
+		{Name: "status", Value: string(obj.Status)},
+		{Name: "old_rate", Value: oldRate},
+		{Name: "new_rate", Value: newRate},
+		{Name: "change", Value: delta},
+		{Name: "change_percent", Value: percent},
+	}
+}
+
+// key matches currencies by CharCode, falling back to the CBR ID for entries without one.
+func key(item *currency.Currency) string {
+	if item.CharCode != "" {
+		return strings.ToUpper(item.CharCode)
+	}
+
+	return "#" + item.ID
+}
+
+func base(rates *currency.Rates) string {
+	if rates.Base == "" {
+		return converter.BaseCode
+	}
+
+	return strings.ToUpper(rates.Base)
+}
+
+func index(rates *currency.Rates) map[string]*currency.Currency {
+	result := make(map[string]*currency.Currency, len(rates.Data))
+	for idx := range rates.Data {
+		result[key(&rates.Data[idx])] = &rates.Data[idx]
+	}
+
+	return result
+}
+
+func describe(item *currency.Currency, change *Change) {
+	change.CharCode = item.CharCode
+	change.ID = item.ID
+	change.Name = item.Name
+}
+
+// Compare lists changed currencies by the size of the relative move, largest
+// first, followed by unchanged, added and removed ones. Percentages are
+// rounded to places.
+func Compare(before, after currency.Rates, places int32) ([]Change, error) {
+	if base(&before) != base(&after) {
+		return nil, fmt.Errorf("%w: %s and %s", ErrBaseMismatch, base(&before), base(&after))
+	}
+
+	previous := index(&before)
+	result := make([]Change, 0, len(after.Data))
+
+	for idx := range after.Data {
+		item := &after.Data[idx]
+		change := Change{Status: StatusAdded, New: item.Rate}
+		describe(item, &change)
+
+		if old, exists := previous[key(item)]; exists {
+			delete(previous, key(item))
+
+			change.Old = old.Rate
+			change.Delta = item.Rate.Sub(old.Rate)
+			change.Status = StatusChanged
+
+			if change.Delta.IsZero() {
+				change.Status = StatusUnchanged
+			}
+
+			if !old.Rate.IsZero() {
+				ratio, _ := change.Delta.Mul(decimal.FromInt(percentScale)).Div(old.Rate, places)
+				change.Percent = ratio
+			}
+		}
+
+		result = append(result, change)
+	}
+
+	for idx := range before.Data {
+		item := &before.Data[idx]
+		if _, removed := previous[key(item)]; removed {
+			change := Change{Status: StatusRemoved, Old: item.Rate}
+			describe(item, &change)
+			result = append(result, change)
+		}
+	}
+
+	slices.SortStableFunc(result, compareChanges)
+
+	return result, nil
+}
+
+var statusOrder = map[Status]int{ //nolint:gochecknoglobals
+	StatusChanged:   0,
+	StatusUnchanged: 1,
+	StatusAdded:     2, //nolint:mnd
+	StatusRemoved:   3, //nolint:mnd
+}
+
+func compareChanges(lhs, rhs Change) int {
+	if order := statusOrder[lhs.Status] - statusOrder[rhs.Status]; order != 0 {
+		return order
+	}
+
+	if order := rhs.Percent.Abs().Cmp(lhs.Percent.Abs()); order != 0 {
+		return order
+	}
+
+	if order := rhs.Delta.Abs().Cmp(lhs.Delta.Abs()); order != 0 {
+		return order
+	}
+
+	return strings.Compare(lhs.CharCode, rhs.CharCode)
+}
+
+func Columns() []string {
+	var change Change
+
+	return change.Record().Names()
+}
+
+func Records(changes []Change) []output.Record {
+	records := make([]output.Record, len(changes))
+	for idx := range changes {
+		records[idx] = changes[idx].Record()
+	}
+
+	return records
+}
@@ -0,0 +1,53 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshot(base string, rates map[string]string) currency.Rates {
+	result := currency.Rates{Base: base}
+
+	for code, rate := range rates {
+		result.Data = append(result.Data, currency.Currency{CharCode: code, Rate: decimal.MustParse(rate)})
+	}
+
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	before := snapshot("", map[string]string{"USD": "90", "EUR": "100", "CNY": "12.5", "GBP": "115"})
+	after := snapshot("RUB", map[string]string{"USD": "91.8", "EUR": "99", "CNY": "12.5", "JPY": "0.6"})
+
+	changes, err := diff.Compare(before, after, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 5)
+
+	codes := make([]string, len(changes))
+	for idx := range changes {
+		codes[idx] = changes[idx].CharCode
+	}
+
+	assert.Equal(t, []string{"USD", "EUR", "CNY", "JPY", "GBP"}, codes)
+	assert.Equal(t, diff.StatusChanged, changes[0].Status)
+	assert.Equal(t, "1.8", changes[0].Delta.String())
+	assert.Equal(t, "2.00", changes[0].Percent.String())
+	assert.Equal(t, "-1.00", changes[1].Percent.String())
+	assert.Equal(t, diff.StatusUnchanged, changes[2].Status)
+	assert.Equal(t, diff.StatusAdded, changes[3].Status)
+	assert.Equal(t, diff.StatusRemoved, changes[4].Status)
+
+	record := changes[3].Record()
+	assert.Nil(t, record[4].Value)
+	assert.Equal(t, "new_rate", record[5].Name)
+
+	_, err = diff.Compare(before, snapshot("EUR", nil), 2)
+	require.ErrorIs(t, err, diff.ErrBaseMismatch)
+}
@@ -0,0 +1,64 @@
+// Package iso4217 holds the list of active ISO 4217 currency codes.
+package iso4217
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NoMinorUnits marks codes without a minor unit, such as XDR or the precious metals.
+const NoMinorUnits = -1
+
+type Currency struct {
+	Code       string
+	Number     uint
+	MinorUnits int
+	Name       string
+}
+
+//go:embed iso4217.csv
+var table string
+
+//nolint:gochecknoglobals
+var (
+	parseOnce sync.Once
+	byCode    map[string]Currency
+)
+
+func load() {
+	rows, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	if err != nil {
+		panic("iso4217: broken embedded table: " + err.Error())
+	}
+
+	byCode = make(map[string]Currency, len(rows))
+
+	for _, row := range rows[1:] {
+		number, err := strconv.ParseUint(row[1], 10, 32)
+		if err != nil {
+			panic("iso4217: broken embedded table: " + err.Error())
+		}
+
+		minor := NoMinorUnits
+		if row[2] != "" {
+			minor, err = strconv.Atoi(row[2])
+			if err != nil {
+				panic("iso4217: broken embedded table: " + err.Error())
+			}
+		}
+
+		byCode[row[0]] = Currency{row[0], uint(number), minor, row[3]}
+	}
+}
+
+// Lookup finds a currency by its alphabetic code, case-insensitively.
+func Lookup(code string) (Currency, bool) {
+	parseOnce.Do(load)
+
+	result, found := byCode[strings.ToUpper(code)]
+
+	return result, found
+}
@@ -0,0 +1,24 @@
+package iso4217_test
+
+import (
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/iso4217"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	entry, found := iso4217.Lookup("jpy")
+	require.True(t, found)
+	assert.Equal(t, iso4217.Currency{Code: "JPY", Number: 392, MinorUnits: 0, Name: "Yen"}, entry)
+
+	entry, found = iso4217.Lookup("XAU")
+	require.True(t, found)
+	assert.Equal(t, iso4217.NoMinorUnits, entry.MinorUnits)
+
+	_, found = iso4217.Lookup("RUR")
+	assert.False(t, found)
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+type CSVWriter struct{}
+
+func init() { //nolint:gochecknoinits
+	Register("csv", CSVWriter{}, ".csv")
+}
+
+type csvStream struct {
+	encoder *csv.Writer
+	started bool
+}
+
+func (obj *csvStream) WriteHeader(names []string) error {
+	if obj.started {
+		return nil
+	}
+
+	obj.started = true
+
+	err := obj.encoder.Write(names)
+	if err != nil {
+		return fmt.Errorf("cannot write csv header: %w", err)
+	}
+
+	return nil
+}
+
+func (obj *csvStream) Write(record Record) error {
+	err := obj.WriteHeader(record.Names())
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, len(record))
+	for idx, field := range record {
+		row[idx] = formatValue(field.Value)
+	}
+
+	err = obj.encoder.Write(row)
+	if err != nil {
+		return fmt.Errorf("cannot write csv row: %w", err)
+	}
+
+	return nil
+}
+
+func (obj *csvStream) Close() error {
+	obj.encoder.Flush()
+
+	err := obj.encoder.Error()
+	if err != nil {
+		return fmt.Errorf("cannot flush csv: %w", err)
+	}
+
+	return nil
+}
+
+func (CSVWriter) Stream(writer io.Writer) RecordStream {
+	return &csvStream{csv.NewWriter(writer), false}
+}
+
+func (obj CSVWriter) Write(writer io.Writer, records []Record) error {
+	return writeAll(obj, writer, records)
+}
@@ -0,0 +1,204 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DefaultFileMode = os.FileMode(0o644)
+	DefaultDirMode  = os.FileMode(0o755)
+
+	backupSuffix = ".bak"
+)
+
+var ErrOverwriteInput = errors.New("refusing to overwrite the input file")
+
+type FileOptions struct {
+	FileMode os.FileMode
+	DirMode  os.FileMode
+	// Backup keeps the previous output next to the new one with a ".bak" suffix.
+	Backup bool
+	// Protected lists files that must never be replaced, such as the input.
+	Protected []string
+}
+
+func (obj FileOptions) withDefaults() FileOptions {
+	if obj.FileMode == 0 {
+		obj.FileMode = DefaultFileMode
+	}
+
+	if obj.DirMode == 0 {
+		obj.DirMode = DefaultDirMode
+	}
+
+	return obj
+}
+
+func samePath(left string, right string) bool {
+	leftInfo, leftErr := os.Stat(left)
+	rightInfo, rightErr := os.Stat(right)
+
+	if leftErr == nil && rightErr == nil {
+		return os.SameFile(leftInfo, rightInfo)
+	}
+
+	leftAbs, leftErr := filepath.Abs(left)
+	rightAbs, rightErr := filepath.Abs(right)
+
+	return leftErr == nil && rightErr == nil && leftAbs == rightAbs
+}
+
+// AtomicFile collects output in a temporary file next to the target, so readers
+// only ever see the previous content or the complete new one.
+type AtomicFile struct {
+	path string
+	opts FileOptions
+	temp *os.File
+}
+
+func CreateFile(path string, opts FileOptions) (*AtomicFile, error) {
+	opts = opts.withDefaults()
+
+	for _, protected := range opts.Protected {
+		if protected != "" && samePath(path, protected) {
+			return nil, fmt.Errorf("%w: %s", ErrOverwriteInput, path)
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), opts.DirMode)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create required directories: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create temporary output file: %w", err)
+	}
+
+	return &AtomicFile{path, opts, temp}, nil
+}
+
+func (obj *AtomicFile) Write(data []byte) (int, error) {
+	return obj.temp.Write(data) //nolint:wrapcheck
+}
+
+// Abort drops the temporary file and leaves the target untouched.
+func (obj *AtomicFile) Abort() {
+	_ = obj.temp.Close()
+	_ = os.Remove(obj.temp.Name())
+}
+
+func (obj *AtomicFile) Commit() error {
+	err := obj.commit()
+	if err != nil {
+		obj.Abort()
+
+		return fmt.Errorf("cannot write output file: %w", err)
+	}
+
+	return nil
+}
+
+func (obj *AtomicFile) commit() error {
+	err := obj.temp.Chmod(obj.opts.FileMode)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	err = obj.temp.Sync()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	err = obj.temp.Close()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if obj.opts.Backup {
+		err = backup(obj.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.Rename(obj.temp.Name(), obj.path)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return syncDir(filepath.Dir(obj.path))
+}
+
+// WriteBytes replaces the file at path with data the same way CreateFile does.
+func WriteBytes(path string, data []byte, opts FileOptions) error {
+	file, err := CreateFile(path, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err != nil {
+		file.Abort()
+
+		return fmt.Errorf("cannot write output file: %w", err)
+	}
+
+	return file.Commit()
+}
+
+// backup links the current output to its ".bak" name, copying when links are unsupported.
+func backup(path string) error {
+	target := path + backupSuffix
+
+	err := os.Remove(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot replace backup: %w", err)
+	}
+
+	err = os.Link(path, target)
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	source, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot back up previous output: %w", err)
+	}
+	defer source.Close()
+
+	info, err := source.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot back up previous output: %w", err)
+	}
+
+	copied, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return fmt.Errorf("cannot back up previous output: %w", err)
+	}
+	defer copied.Close()
+
+	_, err = io.Copy(copied, source)
+	if err != nil {
+		return fmt.Errorf("cannot back up previous output: %w", err)
+	}
+
+	return copied.Close() //nolint:wrapcheck
+}
+
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer dir.Close()
+
+	// some platforms cannot fsync directories; the rename itself already happened
+	_ = dir.Sync()
+
+	return nil
+}
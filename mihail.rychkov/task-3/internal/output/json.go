@@ -0,0 +1,120 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type JSONWriter struct{}
+
+type NDJSONWriter struct{}
+
+func init() { //nolint:gochecknoinits
+	Register("json", JSONWriter{}, ".json")
+	Register("ndjson", NDJSONWriter{}, ".ndjson", ".jsonl")
+}
+
+func (obj Record) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+
+	buffer.WriteByte('{')
+
+	for idx, field := range obj {
+		if idx > 0 {
+			buffer.WriteByte(',')
+		}
+
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize field name: %w", err)
+		}
+
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize field %q: %w", field.Name, err)
+		}
+
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+type jsonStream struct {
+	writer io.Writer
+	count  int
+}
+
+func (obj *jsonStream) Write(record Record) error {
+	serialized, err := json.MarshalIndent(record, "\t", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to serialize data to json: %w", err)
+	}
+
+	separator := ",\n\t"
+	if obj.count == 0 {
+		separator = "[\n\t"
+	}
+
+	obj.count++
+
+	_, err = io.WriteString(obj.writer, separator+string(serialized))
+	if err != nil {
+		return fmt.Errorf("cannot write json: %w", err)
+	}
+
+	return nil
+}
+
+func (obj *jsonStream) Close() error {
+	closing := "\n]\n"
+	if obj.count == 0 {
+		closing = "[]\n"
+	}
+
+	_, err := io.WriteString(obj.writer, closing)
+	if err != nil {
+		return fmt.Errorf("cannot write json: %w", err)
+	}
+
+	return nil
+}
+
+func (JSONWriter) Stream(writer io.Writer) RecordStream {
+	return &jsonStream{writer, 0}
+}
+
+func (obj JSONWriter) Write(writer io.Writer, records []Record) error {
+	return writeAll(obj, writer, records)
+}
+
+type ndjsonStream struct {
+	encoder *json.Encoder
+}
+
+func (obj *ndjsonStream) Write(record Record) error {
+	err := obj.encoder.Encode(record)
+	if err != nil {
+		return fmt.Errorf("failed to serialize record to ndjson: %w", err)
+	}
+
+	return nil
+}
+
+func (obj *ndjsonStream) Close() error {
+	return nil
+}
+
+func (NDJSONWriter) Stream(writer io.Writer) RecordStream {
+	return &ndjsonStream{json.NewEncoder(writer)}
+}
+
+func (obj NDJSONWriter) Write(writer io.Writer, records []Record) error {
+	return writeAll(obj, writer, records)
+}
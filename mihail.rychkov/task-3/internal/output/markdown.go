@@ -0,0 +1,87 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type MarkdownWriter struct{}
+
+func init() { //nolint:gochecknoinits
+	Register("markdown", MarkdownWriter{}, ".md", ".markdown")
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for idx, cell := range cells {
+		escaped[idx] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+
+	return "| " + strings.Join(escaped, " | ") + " |\n"
+}
+
+type markdownStream struct {
+	writer  io.Writer
+	started bool
+}
+
+func markdownHeader(names []string) string {
+	separators := make([]string, len(names))
+	for idx := range separators {
+		separators[idx] = "---"
+	}
+
+	return markdownRow(names) + markdownRow(separators)
+}
+
+func (obj *markdownStream) WriteHeader(names []string) error {
+	if obj.started {
+		return nil
+	}
+
+	obj.started = true
+
+	_, err := io.WriteString(obj.writer, markdownHeader(names))
+	if err != nil {
+		return fmt.Errorf("cannot write markdown: %w", err)
+	}
+
+	return nil
+}
+
+func (obj *markdownStream) Write(record Record) error {
+	var builder strings.Builder
+
+	if !obj.started {
+		obj.started = true
+
+		builder.WriteString(markdownHeader(record.Names()))
+	}
+
+	cells := make([]string, len(record))
+	for idx, field := range record {
+		cells[idx] = formatValue(field.Value)
+	}
+
+	builder.WriteString(markdownRow(cells))
+
+	_, err := io.WriteString(obj.writer, builder.String())
+	if err != nil {
+		return fmt.Errorf("cannot write markdown: %w", err)
+	}
+
+	return nil
+}
+
+func (obj *markdownStream) Close() error {
+	return nil
+}
+
+func (MarkdownWriter) Stream(writer io.Writer) RecordStream {
+	return &markdownStream{writer, false}
+}
+
+func (obj MarkdownWriter) Write(writer io.Writer, records []Record) error {
+	return writeAll(obj, writer, records)
+}
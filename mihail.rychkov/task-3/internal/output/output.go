@@ -0,0 +1,172 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/compress"
+)
+
+const defaultFormat = "json"
+
+var ErrUnknownFormat = errors.New("unknown output format")
+
+type Field struct {
+	Name  string
+	Value any
+}
+
+type Record []Field
+
+type Writer interface {
+	Write(writer io.Writer, records []Record) error
+}
+
+type registration struct {
+	writer     Writer
+	extensions []string
+}
+
+var registry = map[string]registration{} //nolint:gochecknoglobals
+
+func Register(name string, writer Writer, extensions ...string) {
+	registry[name] = registration{writer, extensions}
+}
+
+func Formats() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func Lookup(name string) (Writer, error) {
+	entry, exists := registry[strings.ToLower(name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownFormat, name, strings.Join(Formats(), ", "))
+	}
+
+	return entry.writer, nil
+}
+
+// FormatFromPath infers the format from the file extension, falling back to json.
+// Compression extensions are looked through: "rates.csv.gz" is csv.
+func FormatFromPath(path string) string {
+	_, path = compress.FromPath(path)
+	ext := strings.ToLower(filepath.Ext(path))
+
+	for name, entry := range registry {
+		for _, candidate := range entry.extensions {
+			if candidate == ext {
+				return name
+			}
+		}
+	}
+
+	return defaultFormat
+}
+
+// Extension returns the preferred file extension of a format, e.g. ".csv".
+func Extension(name string) string {
+	entry, exists := registry[strings.ToLower(name)]
+	if !exists || len(entry.extensions) == 0 {
+		return ""
+	}
+
+	return entry.extensions[0]
+}
+
+func Resolve(format string, path string) (Writer, error) {
+	if format == "" {
+		format = FormatFromPath(path)
+	}
+
+	return Lookup(format)
+}
+
+// WriteTable writes the records; tabular formats fall back to a header made of
+// names when there are no records.
+func WriteTable(writer Writer, dst io.Writer, names []string, records []Record) error {
+	streamer, ok := writer.(Streamer)
+	if len(records) > 0 || !ok {
+		return writer.Write(dst, records) //nolint:wrapcheck
+	}
+
+	stream := streamer.Stream(dst)
+
+	err := WriteHeader(stream, names)
+	if err != nil {
+		return err
+	}
+
+	return stream.Close() //nolint:wrapcheck
+}
+
+// WriteFile replaces the file atomically, compressing it when the path ends
+// with .gz, .zst or .zip. Names are the columns used when records is empty.
+func WriteFile(path string, format string, names []string, records []Record, opts FileOptions) error {
+	writer, err := Resolve(format, path)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+
+	compressor, err := compress.NewWriter(path, &buffer)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	err = WriteTable(writer, compressor, names, records)
+	if err == nil {
+		err = compressor.Close()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to serialize output: %w", err)
+	}
+
+	file, err := CreateFile(path, opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(buffer.Bytes())
+	if err != nil {
+		file.Abort()
+
+		return fmt.Errorf("cannot write output file: %w", err)
+	}
+
+	return file.Commit()
+}
+
+func (obj Record) Names() []string {
+	names := make([]string, len(obj))
+	for idx, field := range obj {
+		names[idx] = field.Name
+	}
+
+	return names
+}
+
+func formatValue(value any) string {
+	if value == nil {
+		return ""
+	}
+
+	if stringer, ok := value.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,124 @@
+package output_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleRecords() []output.Record {
+	return []output.Record{
+		{{Name: "char_code", Value: "USD"}, {Name: "rate", Value: decimal.MustParse("92,5730")}},
+		{{Name: "char_code", Value: "A|B"}, {Name: "rate", Value: decimal.MustParse("0.1")}},
+	}
+}
+
+func render(t *testing.T, format string) string {
+	t.Helper()
+
+	writer, err := output.Lookup(format)
+	require.NoError(t, err)
+
+	var buffer bytes.Buffer
+
+	require.NoError(t, writer.Write(&buffer, sampleRecords()))
+
+	return buffer.String()
+}
+
+func TestFormatFromPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "csv", output.FormatFromPath("out/rates.CSV"))
+	assert.Equal(t, "yaml", output.FormatFromPath("rates.yml"))
+	assert.Equal(t, "ndjson", output.FormatFromPath("rates.jsonl"))
+	assert.Equal(t, "markdown", output.FormatFromPath("rates.md"))
+	assert.Equal(t, "json", output.FormatFromPath("rates"))
+
+	_, err := output.Lookup("toml")
+	require.ErrorIs(t, err, output.ErrUnknownFormat)
+}
+
+func TestWriters(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "{\"char_code\":\"USD\",\"rate\":92.5730}\n{\"char_code\":\"A|B\",\"rate\":0.1}\n",
+		render(t, "ndjson"))
+	assert.Equal(t, "char_code,rate\nUSD,92.5730\nA|B,0.1\n", render(t, "csv"))
+	assert.Equal(t, "- char_code: USD\n  rate: 92.5730\n- char_code: A|B\n  rate: 0.1\n", render(t, "yaml"))
+	assert.Equal(t, "| char_code | rate |\n| --- | --- |\n| USD | 92.5730 |\n| A\\|B | 0.1 |\n",
+		render(t, "markdown"))
+	assert.Contains(t, render(t, "xml"), "<Record>\n\t\t<char_code>USD</char_code>\n\t\t<rate>92.5730</rate>")
+	assert.Contains(t, render(t, "json"), "\"rate\": 92.5730")
+}
+
+func TestWriteTableEmpty(t *testing.T) {
+	t.Parallel()
+
+	for format, expected := range map[string]string{
+		"csv":      "char_code,rate\n",
+		"markdown": "| char_code | rate |\n| --- | --- |\n",
+		"ndjson":   "",
+	} {
+		writer, err := output.Lookup(format)
+		require.NoError(t, err)
+
+		var buffer bytes.Buffer
+
+		require.NoError(t, output.WriteTable(writer, &buffer, []string{"char_code", "rate"}, nil), format)
+		assert.Equal(t, expected, buffer.String(), format)
+	}
+
+	var buffer bytes.Buffer
+
+	stream, err := output.OpenStream("csv", "", &buffer)
+	require.NoError(t, err)
+	require.NoError(t, stream.Write(sampleRecords()[0]))
+	require.NoError(t, output.WriteHeader(stream, []string{"other"}))
+	require.NoError(t, stream.Close())
+	assert.Equal(t, "char_code,rate\nUSD,92.5730\n", buffer.String())
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "rates.csv")
+	input := filepath.Join(dir, "input.xml")
+
+	require.NoError(t, os.WriteFile(input, []byte("<ValCurs/>"), 0o600))
+
+	opts := output.FileOptions{FileMode: 0o640, DirMode: 0o750, Backup: true, Protected: []string{input}}
+
+	require.NoError(t, output.WriteFile(path, "", nil, sampleRecords()[:1], opts))
+	require.NoError(t, output.WriteFile(path, "", nil, sampleRecords(), opts))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+
+	previous, err := os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "char_code,rate\nUSD,92.5730\n", string(previous))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	err = output.WriteFile(filepath.Join(dir, ".", "input.xml"), "xml", nil, sampleRecords(), opts)
+	require.ErrorIs(t, err, output.ErrOverwriteInput)
+
+	data, err := os.ReadFile(input)
+	require.NoError(t, err)
+	assert.Equal(t, "<ValCurs/>", string(data))
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrNoStreaming = errors.New("output format does not support streaming")
+
+// RecordStream writes records one by one; Close finishes the document but does not close the writer.
+type RecordStream interface {
+	Write(record Record) error
+	Close() error
+}
+
+// HeaderStream is implemented by tabular streams. WriteHeader writes the header
+// unless a record already did, so a table with no rows still has its columns.
+type HeaderStream interface {
+	WriteHeader(names []string) error
+}
+
+type Streamer interface {
+	Stream(writer io.Writer) RecordStream
+}
+
+func OpenStream(format string, path string, writer io.Writer) (RecordStream, error) {
+	resolved, err := Resolve(format, path)
+	if err != nil {
+		return nil, err
+	}
+
+	streamer, ok := resolved.(Streamer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNoStreaming, resolved)
+	}
+
+	return streamer.Stream(writer), nil
+}
+
+// WriteHeader writes the header of a tabular stream; other streams ignore it.
+func WriteHeader(stream RecordStream, names []string) error {
+	headed, ok := stream.(HeaderStream)
+	if !ok || len(names) == 0 {
+		return nil
+	}
+
+	return headed.WriteHeader(names)
+}
+
+func writeAll(streamer Streamer, writer io.Writer, records []Record) error {
+	stream := streamer.Stream(writer)
+
+	for _, record := range records {
+		err := stream.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	return stream.Close()
+}
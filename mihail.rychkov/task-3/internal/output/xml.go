@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type XMLWriter struct {
+	Root string
+	Item string
+}
+
+func init() { //nolint:gochecknoinits
+	Register("xml", XMLWriter{"Records", "Record"}, ".xml")
+}
+
+func (obj XMLWriter) Write(writer io.Writer, records []Record) error {
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		return fmt.Errorf("cannot write xml header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "\t")
+
+	root := xml.StartElement{Name: xml.Name{Space: "", Local: obj.Root}, Attr: nil}
+	item := xml.StartElement{Name: xml.Name{Space: "", Local: obj.Item}, Attr: nil}
+
+	err = encoder.EncodeToken(root)
+	if err != nil {
+		return fmt.Errorf("failed to serialize data to xml: %w", err)
+	}
+
+	for _, record := range records {
+		err = encoder.EncodeToken(item)
+		if err != nil {
+			return fmt.Errorf("failed to serialize data to xml: %w", err)
+		}
+
+		for _, field := range record {
+			element := xml.StartElement{Name: xml.Name{Space: "", Local: field.Name}, Attr: nil}
+
+			err = encoder.EncodeElement(formatValue(field.Value), element)
+			if err != nil {
+				return fmt.Errorf("failed to serialize field %q to xml: %w", field.Name, err)
+			}
+		}
+
+		err = encoder.EncodeToken(item.End())
+		if err != nil {
+			return fmt.Errorf("failed to serialize data to xml: %w", err)
+		}
+	}
+
+	err = encoder.EncodeToken(root.End())
+	if err != nil {
+		return fmt.Errorf("failed to serialize data to xml: %w", err)
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("cannot flush xml: %w", err)
+	}
+
+	_, err = io.WriteString(writer, "\n")
+	if err != nil {
+		return fmt.Errorf("cannot write xml: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Rychmick/task-3/internal/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+type YAMLWriter struct{}
+
+func init() { //nolint:gochecknoinits
+	Register("yaml", YAMLWriter{}, ".yaml", ".yml")
+}
+
+func scalarNode(value any) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: formatValue(value)} //nolint:exhaustruct
+
+	switch value.(type) {
+	case decimal.Decimal, float32, float64:
+		node.Tag = "!!float"
+	case int, int64, int32, uint, uint64, uint32:
+		node.Tag = "!!int"
+	case bool:
+		node.Tag = "!!bool"
+	case nil:
+		node.Tag = "!!null"
+	default:
+		node.Tag = "!!str"
+	}
+
+	return node
+}
+
+func (obj Record) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode} //nolint:exhaustruct
+
+	for _, field := range obj {
+		node.Content = append(node.Content, scalarNode(field.Name), scalarNode(field.Value))
+	}
+
+	return node, nil
+}
+
+func (YAMLWriter) Write(writer io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+
+	encoder := yaml.NewEncoder(writer)
+
+	err := encoder.Encode(records)
+	if err != nil {
+		return fmt.Errorf("failed to serialize data to yaml: %w", err)
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return fmt.Errorf("cannot flush yaml: %w", err)
+	}
+
+	return nil
+}
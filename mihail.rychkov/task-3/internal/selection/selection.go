@@ -0,0 +1,217 @@
+package selection
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/output"
+)
+
+const defaultSort = "rate desc"
+
+var (
+	ErrUnknownField = errors.New("unknown field")
+	ErrBadSortKey   = errors.New("invalid sort key")
+	ErrBadOptions   = errors.New("invalid selection")
+)
+
+type Options struct {
+	Sort    []string         `yaml:"sort"`
+	Include []string         `yaml:"include"`
+	Exclude []string         `yaml:"exclude"`
+	MinRate *decimal.Decimal `yaml:"min-rate"`
+	MaxRate *decimal.Decimal `yaml:"max-rate"`
+	Top     int              `yaml:"top"`
+	Fields  []string         `yaml:"fields"`
+}
+
+type comparator func(lhs, rhs *currency.Currency) int
+
+//nolint:gochecknoglobals
+var sortFields = map[string]comparator{
+	"value":      func(lhs, rhs *currency.Currency) int { return lhs.Value.Cmp(rhs.Value) },
+	"vunit_rate": func(lhs, rhs *currency.Currency) int { return lhs.VunitRate.Cmp(rhs.VunitRate) },
+	"rate":       func(lhs, rhs *currency.Currency) int { return lhs.Rate.Cmp(rhs.Rate) },
+	"char_code":  func(lhs, rhs *currency.Currency) int { return strings.Compare(lhs.CharCode, rhs.CharCode) },
+	"num_code":   func(lhs, rhs *currency.Currency) int { return cmp.Compare(lhs.NumCode, rhs.NumCode) },
+	"name":       func(lhs, rhs *currency.Currency) int { return strings.Compare(lhs.Name, rhs.Name) },
+	"nominal":    func(lhs, rhs *currency.Currency) int { return cmp.Compare(lhs.Nominal, rhs.Nominal) },
+}
+
+// parseSortKey accepts "field", "field asc", "field desc" and "-field"; sort
+// keys are named like the output fields they compare.
+func parseSortKey(raw string) (comparator, error) {
+	parts := strings.Fields(strings.ToLower(raw))
+	if len(parts) == 0 || len(parts) > 2 {
+		return nil, fmt.Errorf("%w: %q", ErrBadSortKey, raw)
+	}
+
+	name, descending := strings.CutPrefix(parts[0], "-")
+
+	if len(parts) == 2 {
+		if descending {
+			return nil, fmt.Errorf("%w: %q, use either the - prefix or a direction", ErrBadSortKey, raw)
+		}
+
+		switch parts[1] {
+		case "asc":
+		case "desc":
+			descending = true
+		default:
+			return nil, fmt.Errorf("%w: %q, direction must be asc or desc", ErrBadSortKey, raw)
+		}
+	}
+
+	compare, exists := sortFields[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q in sort key", ErrUnknownField, name)
+	}
+
+	if descending {
+		return func(lhs, rhs *currency.Currency) int { return -compare(lhs, rhs) }, nil
+	}
+
+	return compare, nil
+}
+
+func (obj *Options) comparator() (comparator, error) {
+	keys := obj.Sort
+	if len(keys) == 0 {
+		keys = []string{defaultSort}
+	}
+
+	comparators := make([]comparator, 0, len(keys))
+
+	for _, raw := range keys {
+		compare, err := parseSortKey(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		comparators = append(comparators, compare)
+	}
+
+	return func(lhs, rhs *currency.Currency) int {
+		for _, compare := range comparators {
+			if result := compare(lhs, rhs); result != 0 {
+				return result
+			}
+		}
+
+		return 0
+	}, nil
+}
+
+// Validate reports unusable options up front instead of on the first Apply.
+func (obj *Options) Validate() error {
+	_, err := obj.comparator()
+	if err != nil {
+		return err
+	}
+
+	if obj.Top < 0 {
+		return fmt.Errorf("%w: top must not be negative, got %d", ErrBadOptions, obj.Top)
+	}
+
+	if obj.MinRate != nil && obj.MaxRate != nil && obj.MinRate.Cmp(*obj.MaxRate) > 0 {
+		return fmt.Errorf("%w: min-rate %s is above max-rate %s", ErrBadOptions, obj.MinRate, obj.MaxRate)
+	}
+
+	return nil
+}
+
+func containsCode(codes []string, code string) bool {
+	return slices.ContainsFunc(codes, func(candidate string) bool {
+		return strings.EqualFold(candidate, code)
+	})
+}
+
+// Keep reports whether the item passes the include/exclude lists and rate bounds.
+func (obj *Options) Keep(item *currency.Currency) bool {
+	if len(obj.Include) > 0 && !containsCode(obj.Include, item.CharCode) {
+		return false
+	}
+
+	if containsCode(obj.Exclude, item.CharCode) {
+		return false
+	}
+
+	if obj.MinRate != nil && item.Rate.Cmp(*obj.MinRate) < 0 {
+		return false
+	}
+
+	if obj.MaxRate != nil && item.Rate.Cmp(*obj.MaxRate) > 0 {
+		return false
+	}
+
+	return true
+}
+
+// Ordered reports whether the options need the whole list at once (sorting or top-N).
+func (obj *Options) Ordered() bool {
+	return len(obj.Sort) > 0 || obj.Top > 0
+}
+
+// Apply filters, stably sorts and truncates the list; the input slice is not modified.
+func (obj *Options) Apply(list []currency.Currency) ([]currency.Currency, error) {
+	compare, err := obj.comparator()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]currency.Currency, 0, len(list))
+
+	for idx := range list {
+		if obj.Keep(&list[idx]) {
+			result = append(result, list[idx])
+		}
+	}
+
+	slices.SortStableFunc(result, func(lhs, rhs currency.Currency) int { return compare(&lhs, &rhs) })
+
+	if obj.Top > 0 && len(result) > obj.Top {
+		result = result[:obj.Top]
+	}
+
+	return result, nil
+}
+
+// Columns returns the configured fields, or names when every field is kept.
+func (obj *Options) Columns(names []string) []string {
+	if len(obj.Fields) == 0 {
+		return names
+	}
+
+	return obj.Fields
+}
+
+// Project keeps only the configured fields, in the configured order.
+func (obj *Options) Project(records []output.Record) ([]output.Record, error) {
+	if len(obj.Fields) == 0 {
+		return records, nil
+	}
+
+	result := make([]output.Record, len(records))
+
+	for idx, record := range records {
+		projected := make(output.Record, 0, len(obj.Fields))
+
+		for _, name := range obj.Fields {
+			position := slices.IndexFunc(record, func(field output.Field) bool { return field.Name == name })
+			if position < 0 {
+				return nil, fmt.Errorf("%w: %q in fields", ErrUnknownField, name)
+			}
+
+			projected = append(projected, record[position])
+		}
+
+		result[idx] = projected
+	}
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package selection_test
+
+import (
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/selection"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func item(code string, numCode uint, rate string) currency.Currency {
+	return currency.Currency{
+		ID: "", NumCode: numCode, CharCode: code, Nominal: 1, Name: code,
+		Value: decimal.MustParse(rate), VunitRate: decimal.Decimal{}, Rate: decimal.MustParse(rate),
+	}
+}
+
+func codes(list []currency.Currency) []string {
+	result := make([]string, len(list))
+	for idx := range list {
+		result[idx] = list[idx].CharCode
+	}
+
+	return result
+}
+
+func sample() []currency.Currency {
+	return []currency.Currency{item("USD", 840, "90"), item("EUR", 978, "99"), item("CNY", 156, "12"), item("AMD", 51, "12")}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	var opts selection.Options
+
+	list, err := opts.Apply(sample())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR", "USD", "CNY", "AMD"}, codes(list))
+
+	opts.Sort = []string{"value", "char_code desc"}
+	list, err = opts.Apply(sample())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CNY", "AMD", "USD", "EUR"}, codes(list))
+
+	minRate := decimal.MustParse("20")
+	opts = selection.Options{Sort: []string{"-num_code"}, Exclude: []string{"eur"}, MinRate: &minRate, Top: 1}
+	list, err = opts.Apply(sample())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"USD"}, codes(list))
+
+	opts = selection.Options{Include: []string{"AMD", "CNY"}, Sort: []string{"name asc"}}
+	list, err = opts.Apply(sample())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AMD", "CNY"}, codes(list))
+
+	opts.Sort = []string{"colour"}
+	_, err = opts.Apply(sample())
+	require.ErrorIs(t, err, selection.ErrUnknownField)
+
+	opts.Sort = []string{"value sideways"}
+	_, err = opts.Apply(sample())
+	require.ErrorIs(t, err, selection.ErrBadSortKey)
+
+	opts.Sort = []string{"-value desc"}
+	_, err = opts.Apply(sample())
+	require.ErrorIs(t, err, selection.ErrBadSortKey)
+
+	jpy := item("JPY", 392, "60")
+	jpy.Nominal = 100
+	jpy.Rate = decimal.MustParse("0.6")
+
+	opts = selection.Options{Sort: []string{"value desc"}}
+	list, err = opts.Apply(append(sample(), jpy))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR", "USD", "JPY", "CNY", "AMD"}, codes(list))
+
+	opts.Sort = nil
+	list, err = opts.Apply(append(sample(), jpy))
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", codes(list)[4])
+}
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	rates := currency.Rates{Data: sample()[:1]}
+	opts := selection.Options{Fields: []string{"rate", "char_code"}}
+
+	records, err := opts.Project(rates.Records())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rate", "char_code"}, records[0].Names())
+
+	opts.Fields = []string{"colour"}
+	_, err = opts.Project(rates.Records())
+	require.ErrorIs(t, err, selection.ErrUnknownField)
+}
@@ -0,0 +1,482 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/converter"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/output"
+	"github.com/Rychmick/task-3/internal/selection"
+)
+
+const defaultPlaces = 4
+
+var (
+	ErrNotLoaded  = errors.New("rates are not loaded yet")
+	errBadRequest = errors.New("bad request")
+	errNotFound   = errors.New("not found")
+
+	errNotAcceptable = errors.New("not acceptable")
+)
+
+// mediaTypes lists the negotiable formats in order of preference.
+var mediaTypes = []struct { //nolint:gochecknoglobals
+	mime   string
+	format string
+}{
+	{"application/json", "json"},
+	{"text/csv", "csv"},
+	{"application/xml", "xml"},
+	{"text/xml", "xml"},
+}
+
+type LoadFunc func() (currency.Rates, error)
+
+// Options are the defaults of every request; query parameters override them.
+type Options struct {
+	Selection    selection.Options
+	Precision    *int32
+	BaseCurrency string
+}
+
+type snapshot struct {
+	rates  currency.Rates
+	conv   *converter.Converter
+	loaded time.Time
+}
+
+type Server struct {
+	load    LoadFunc
+	opts    Options
+	current atomic.Pointer[snapshot]
+	mux     *http.ServeMux
+}
+
+func New(load LoadFunc, opts Options) *Server {
+	server := &Server{load: load, opts: opts, current: atomic.Pointer[snapshot]{}, mux: http.NewServeMux()}
+
+	server.mux.HandleFunc("GET /rates", server.listRates)
+	server.mux.HandleFunc("GET /rates/{code}", server.getRate)
+	server.mux.HandleFunc("GET /convert", server.convert)
+	server.mux.HandleFunc("GET /healthz", server.health)
+
+	return server
+}
+
+func (obj *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	obj.mux.ServeHTTP(writer, request)
+}
+
+// Reload swaps in freshly loaded rates. On failure the previous rates keep being served.
+func (obj *Server) Reload() error {
+	rates, err := obj.load()
+	if err != nil {
+		return err
+	}
+
+	conv, err := converter.New(rates)
+	if err != nil {
+		return fmt.Errorf("cannot index rates: %w", err)
+	}
+
+	obj.current.Store(&snapshot{rates, conv, time.Now()})
+
+	return nil
+}
+
+func (obj *Server) places() int32 {
+	if obj.opts.Precision != nil {
+		return *obj.opts.Precision
+	}
+
+	return defaultPlaces
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(writer http.ResponseWriter, status int, data any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	_ = json.NewEncoder(writer).Encode(data)
+}
+
+func writeError(writer http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, ErrNotLoaded):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, errNotFound), errors.Is(err, converter.ErrUnknownCurrency):
+		status = http.StatusNotFound
+	case errors.Is(err, errBadRequest), errors.Is(err, selection.ErrBadSortKey),
+		errors.Is(err, selection.ErrUnknownField), errors.Is(err, selection.ErrBadOptions):
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(writer, status, errorResponse{err.Error()})
+}
+
+type acceptedType struct {
+	mime    string
+	quality float64
+}
+
+// acceptedTypes lists the media ranges of an Accept header by descending q,
+// keeping the header order among equal ones. Ranges with q=0 come last.
+func acceptedTypes(accept string) []acceptedType {
+	var result []acceptedType
+
+	for _, part := range strings.Split(accept, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+
+		if raw, found := params["q"]; found {
+			quality, err = strconv.ParseFloat(raw, 64)
+			if err != nil || quality < 0 || quality > 1 {
+				continue
+			}
+		}
+
+		result = append(result, acceptedType{accepted, quality})
+	}
+
+	slices.SortStableFunc(result, func(lhs, rhs acceptedType) int { return cmp.Compare(rhs.quality, lhs.quality) })
+
+	return result
+}
+
+// negotiate picks the response format from ?format= or the supported type with
+// the highest q in the Accept header, defaulting to JSON.
+func negotiate(request *http.Request) (string, string, error) {
+	if format := request.URL.Query().Get("format"); format != "" {
+		for _, media := range mediaTypes {
+			if strings.EqualFold(media.format, format) {
+				return media.format, media.mime, nil
+			}
+		}
+
+		return "", "", fmt.Errorf("%w: unsupported format %q", errBadRequest, format)
+	}
+
+	accept := request.Header.Get("Accept")
+	if accept == "" {
+		return mediaTypes[0].format, mediaTypes[0].mime, nil
+	}
+
+	accepted := acceptedTypes(accept)
+
+	// q=0 refuses a type even when a wildcard would match it
+	refused := make(map[string]bool)
+
+	for _, item := range accepted {
+		if item.quality == 0 {
+			refused[item.mime] = true
+		}
+	}
+
+	for _, item := range accepted {
+		if item.quality == 0 {
+			break
+		}
+
+		for _, media := range mediaTypes {
+			if refused[media.mime] {
+				continue
+			}
+
+			wildcard := item.mime == "*/*" || (item.mime == "application/*" && media.format == "json")
+			if item.mime == media.mime || wildcard {
+				return media.format, media.mime, nil
+			}
+		}
+	}
+
+	return "", "", fmt.Errorf("%w: none of %q is supported", errNotAcceptable, accept)
+}
+
+// respond writes the records in the negotiated format; columns name the header
+// of an empty table.
+func respond(
+	writer http.ResponseWriter, request *http.Request, columns []string, records []output.Record, single bool,
+) {
+	format, media, err := negotiate(request)
+	if errors.Is(err, errNotAcceptable) {
+		writeJSON(writer, http.StatusNotAcceptable, errorResponse{err.Error()})
+
+		return
+	}
+
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", media)
+	writer.Header().Add("Vary", "Accept")
+
+	if format == "json" && single {
+		writeJSON(writer, http.StatusOK, records[0])
+
+		return
+	}
+
+	encoder, err := output.Lookup(format)
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	_ = output.WriteTable(encoder, writer, columns, records)
+}
+
+func (obj *Server) snapshot() (*snapshot, error) {
+	current := obj.current.Load()
+	if current == nil {
+		return nil, ErrNotLoaded
+	}
+
+	return current, nil
+}
+
+func splitList(values []string) []string {
+	var result []string
+
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+
+	return result
+}
+
+func parseBound(raw string, name string) (*decimal.Decimal, error) {
+	value, err := decimal.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", errBadRequest, name, err)
+	}
+
+	return &value, nil
+}
+
+// selectionFor overlays the query parameters sort, include, exclude, min-rate,
+// max-rate, top and fields on the configured selection.
+func (obj *Server) selectionFor(request *http.Request) (selection.Options, error) {
+	query := request.URL.Query()
+	result := obj.opts.Selection
+
+	lists := map[string]*[]string{
+		"sort": &result.Sort, "include": &result.Include, "exclude": &result.Exclude, "fields": &result.Fields,
+	}
+
+	for name, target := range lists {
+		if query.Has(name) {
+			*target = splitList(query[name])
+		}
+	}
+
+	var err error
+
+	if raw := query.Get("min-rate"); raw != "" {
+		result.MinRate, err = parseBound(raw, "min-rate")
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if raw := query.Get("max-rate"); raw != "" {
+		result.MaxRate, err = parseBound(raw, "max-rate")
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if raw := query.Get("top"); raw != "" {
+		result.Top, err = strconv.Atoi(raw)
+		if err != nil {
+			return result, fmt.Errorf("%w: top must be an integer", errBadRequest)
+		}
+	}
+
+	return result, result.Validate()
+}
+
+// table returns the rates in the requested base (?base=, else the configured one).
+func (obj *Server) table(current *snapshot, request *http.Request) (currency.Rates, error) {
+	base := request.URL.Query().Get("base")
+	if base == "" {
+		base = obj.opts.BaseCurrency
+	}
+
+	if base == "" {
+		return current.rates, nil
+	}
+
+	rebased, err := current.conv.Rebase(base)
+	if err != nil {
+		return rebased, fmt.Errorf("cannot rebase rates: %w", err)
+	}
+
+	rebased.Date = current.rates.Date
+	rebased.Name = current.rates.Name
+
+	return rebased, nil
+}
+
+func (obj *Server) listRates(writer http.ResponseWriter, request *http.Request) {
+	current, err := obj.snapshot()
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	opts, err := obj.selectionFor(request)
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	rates, err := obj.table(current, request)
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	rates.Data, err = opts.Apply(rates.Data)
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	if obj.opts.Precision != nil {
+		rates.Round(*obj.opts.Precision)
+	}
+
+	records, err := opts.Project(rates.Records())
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	respond(writer, request, opts.Columns(currency.Columns(false)), records, false)
+}
+
+func (obj *Server) getRate(writer http.ResponseWriter, request *http.Request) {
+	current, err := obj.snapshot()
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	rates, err := obj.table(current, request)
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	code := request.PathValue("code")
+
+	item, found := rates.Find(code)
+	if !found {
+		writeError(writer, fmt.Errorf("%w: currency %s", errNotFound, code))
+
+		return
+	}
+
+	if obj.opts.Precision != nil {
+		item.Round(*obj.opts.Precision)
+	}
+
+	respond(writer, request, nil, []output.Record{item.Record()}, true)
+}
+
+func (obj *Server) convert(writer http.ResponseWriter, request *http.Request) {
+	current, err := obj.snapshot()
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	query := request.URL.Query()
+	from, to := strings.ToUpper(query.Get("from")), strings.ToUpper(query.Get("to"))
+
+	if from == "" || to == "" {
+		writeError(writer, fmt.Errorf("%w: from and to are required", errBadRequest))
+
+		return
+	}
+
+	amount := decimal.FromInt(1)
+
+	if raw := query.Get("amount"); raw != "" {
+		amount, err = decimal.Parse(raw)
+		if err != nil {
+			writeError(writer, fmt.Errorf("%w: amount: %w", errBadRequest, err))
+
+			return
+		}
+	}
+
+	result, err := current.conv.Convert(amount, from, to, obj.places())
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	respond(writer, request, nil, []output.Record{{
+		{Name: "from", Value: from},
+		{Name: "to", Value: to},
+		{Name: "amount", Value: amount},
+		{Name: "result", Value: result},
+		{Name: "date", Value: current.rates.Date},
+	}}, true)
+}
+
+type healthResponse struct {
+	Status     string    `json:"status"`
+	Loaded     time.Time `json:"loaded"`
+	Date       string    `json:"date"`
+	Currencies int       `json:"currencies"`
+}
+
+func (obj *Server) health(writer http.ResponseWriter, _ *http.Request) {
+	current, err := obj.snapshot()
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, healthResponse{"ok", current.loaded, current.rates.Date, len(current.rates.Data)})
+}
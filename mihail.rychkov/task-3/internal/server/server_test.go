@@ -0,0 +1,143 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/selection"
+	"github.com/Rychmick/task-3/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBroken = errors.New("broken input")
+
+func sampleRates() currency.Rates {
+	rates := currency.Rates{Date: "01.03.2024", Name: "Foreign Currency Market", Base: "", Data: []currency.Currency{
+		{ID: "R01235", NumCode: 840, CharCode: "USD", Nominal: 1, Name: "Доллар США", Value: decimal.MustParse("90")},
+		{ID: "R01239", NumCode: 978, CharCode: "EUR", Nominal: 1, Name: "Евро", Value: decimal.MustParse("99")},
+		{ID: "R01375", NumCode: 156, CharCode: "CNY", Nominal: 10, Name: "Юань", Value: decimal.MustParse("125")},
+	}}
+
+	_ = rates.Normalize()
+
+	return rates
+}
+
+func doRequest(t *testing.T, handler http.Handler, target string, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func decodeBody[R any](t *testing.T, recorder *httptest.ResponseRecorder) R {
+	t.Helper()
+
+	var result R
+
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+
+	return result
+}
+
+func TestRates(t *testing.T) {
+	t.Parallel()
+
+	places := int32(2)
+	service := server.New(func() (currency.Rates, error) { return sampleRates(), nil },
+		server.Options{Selection: selection.Options{Fields: []string{"char_code", "rate"}}, Precision: &places})
+
+	recorder := doRequest(t, service, "/healthz", "")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	require.NoError(t, service.Reload())
+
+	rates := decodeBody[[]map[string]any](t, doRequest(t, service, "/rates", ""))
+	require.Len(t, rates, 3)
+	assert.Equal(t, "EUR", rates[0]["char_code"])
+	assert.InDelta(t, 12.5, rates[2]["rate"], 1e-9)
+
+	recorder = doRequest(t, service, "/rates?sort=char_code&exclude=usd&fields=char_code", "text/csv")
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "char_code\nCNY\nEUR\n", recorder.Body.String())
+
+	recorder = doRequest(t, service, "/rates?top=1", "application/xml;q=0.9, text/html")
+	assert.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "<char_code>EUR</char_code>")
+
+	recorder = doRequest(t, service, "/rates", "text/html")
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+
+	recorder = doRequest(t, service, "/rates?top=1", "application/json;q=0.5, text/csv")
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+
+	recorder = doRequest(t, service, "/rates?top=1", "text/csv;q=0, */*;q=0.1")
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	recorder = doRequest(t, service, "/rates", "application/json;q=0")
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+
+	recorder = doRequest(t, service, "/rates?top=1", "application/json;q=0, */*")
+	assert.NotEqual(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = doRequest(t, service, "/rates?sort=colour", "")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	rate := decodeBody[map[string]any](t, doRequest(t, service, "/rates/usd?base=EUR", ""))
+	assert.Equal(t, "USD", rate["char_code"])
+	assert.InDelta(t, 0.91, rate["rate"], 1e-9)
+
+	recorder = doRequest(t, service, "/rates/XXX", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestConvertAndReload(t *testing.T) {
+	t.Parallel()
+
+	broken := false
+	service := server.New(func() (currency.Rates, error) {
+		if broken {
+			return currency.Rates{}, errBroken
+		}
+
+		return sampleRates(), nil
+	}, server.Options{})
+
+	require.NoError(t, service.Reload())
+
+	result := decodeBody[map[string]any](t, doRequest(t, service, "/convert?from=usd&to=cny&amount=100", ""))
+	assert.InDelta(t, 720.0, result["result"], 1e-9)
+	assert.Equal(t, "01.03.2024", result["date"])
+
+	recorder := doRequest(t, service, "/convert?from=USD&to=EUR&amount=ten", "")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = doRequest(t, service, "/convert?from=USD&to=XXX", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = doRequest(t, service, "/convert?from=USD&to=EUR&format=csv", "")
+	assert.True(t, strings.HasPrefix(recorder.Body.String(), "from,to,amount,result,date\nUSD,EUR,1,0.9091,"))
+
+	broken = true
+	require.ErrorIs(t, service.Reload(), errBroken)
+
+	health := decodeBody[map[string]any](t, doRequest(t, service, "/healthz", ""))
+	assert.Equal(t, "ok", health["status"])
+	assert.InDelta(t, 3, health["currencies"], 0)
+}
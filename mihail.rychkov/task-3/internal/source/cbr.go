@@ -0,0 +1,21 @@
+package source
+
+import (
+	"io"
+
+	"github.com/Rychmick/task-3/internal/converter"
+	"github.com/Rychmick/task-3/internal/currency"
+)
+
+type cbrParser struct{}
+
+func (cbrParser) Parse(reader io.Reader, collector *currency.Collector) (currency.Rates, error) {
+	rates, err := currency.DecodeWith(reader, collector)
+	rates.Base = converter.BaseCode
+
+	return rates, err //nolint:wrapcheck
+}
+
+func init() { //nolint:gochecknoinits
+	Register("cbr", cbrParser{}, Detection{Root: "ValCurs", Prefixes: "", Extensions: []string{".xml"}})
+}
@@ -0,0 +1,105 @@
+package source
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/xml"
+)
+
+var ErrMissingColumn = errors.New("required column is missing")
+
+// csvParser reads a header row followed by one currency per row. Columns are
+// matched by name; char_code and value (or rate) are required, date and base
+// are taken from the first row that has them.
+type csvParser struct{}
+
+func (csvParser) Parse(reader io.Reader, collector *currency.Collector) (currency.Rates, error) {
+	var result currency.Rates
+
+	records := csv.NewReader(reader)
+	records.FieldsPerRecord = -1
+	records.TrimLeadingSpace = true
+
+	header, err := records.Read()
+	if err != nil {
+		return result, fmt.Errorf("cannot read csv header: %w", err)
+	}
+
+	// a single column means the file is most likely separated by semicolons
+	if len(header) == 1 && strings.Contains(header[0], ";") {
+		records.Comma = ';'
+		header = strings.Split(header[0], ";")
+	}
+
+	columns := make([]string, len(header))
+	for idx, name := range header {
+		columns[idx] = canonical(name)
+	}
+
+	if !slices.Contains(columns, "charcode") || (!slices.Contains(columns, "value") && !slices.Contains(columns, "rate")) {
+		return result, fmt.Errorf("%w: need char_code and value or rate, got %s",
+			ErrMissingColumn, strings.Join(header, ", "))
+	}
+
+	for idx := 0; ; idx++ {
+		row, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+
+		if err != nil {
+			return result, fmt.Errorf("cannot read csv rates: %w", err)
+		}
+
+		fields := make(map[string]string, len(row))
+		for col, value := range row {
+			if col < len(columns) {
+				fields[columns[col]] = value
+			}
+		}
+
+		err = fillSnapshot(&result, fields)
+		if err != nil {
+			return result, err
+		}
+
+		line, column := records.FieldPos(0)
+		raw := rawFromFields(fields)
+
+		item, keep, err := collector.Accept(xml.Position{Index: idx, Element: "row", Line: line, Column: column}, &raw)
+		if err != nil {
+			return result, err //nolint:wrapcheck
+		}
+
+		if keep {
+			result.Data = append(result.Data, item)
+		}
+	}
+}
+
+func fillSnapshot(result *currency.Rates, fields map[string]string) error {
+	if result.Date == "" && fields["date"] != "" {
+		date, err := normalizeDate(fields["date"])
+		if err != nil {
+			return fmt.Errorf("invalid snapshot date: %w", err)
+		}
+
+		result.Date = date
+	}
+
+	if result.Base == "" {
+		result.Base = strings.ToUpper(strings.TrimSpace(fields["base"]))
+	}
+
+	return nil
+}
+
+func init() { //nolint:gochecknoinits
+	Register("csv", csvParser{}, Detection{Root: "", Prefixes: "", Extensions: []string{".csv"}})
+}
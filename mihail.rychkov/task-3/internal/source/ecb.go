@@ -0,0 +1,113 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/decimal"
+	"github.com/Rychmick/task-3/internal/xml"
+)
+
+const (
+	ecbBase = "EUR"
+	// ECB quotes "1 EUR = rate units"; the inverted price keeps this many places.
+	ecbPlaces = 10
+)
+
+var errZeroRate = errors.New("rate is zero")
+
+type ecbRate struct {
+	Currency string `xml:"currency,attr"`
+	Rate     string `xml:"rate,attr"`
+}
+
+type ecbDay struct {
+	Time  string    `xml:"time,attr"`
+	Rates []ecbRate `xml:"Cube"`
+}
+
+type ecbEnvelope struct {
+	Sender string   `xml:"Sender>name"`
+	Days   []ecbDay `xml:"Cube>Cube"`
+}
+
+// ecbParser reads eurofxref documents. Historical files hold many days, newest
+// first; only the most recent one becomes the snapshot.
+type ecbParser struct{}
+
+func (ecbParser) Parse(reader io.Reader, collector *currency.Collector) (currency.Rates, error) {
+	var (
+		result   currency.Rates
+		envelope ecbEnvelope
+	)
+
+	result.Base = ecbBase
+
+	err := xml.Parse(reader, &envelope)
+	if err != nil {
+		return result, err //nolint:wrapcheck
+	}
+
+	result.Name = envelope.Sender
+
+	if len(envelope.Days) == 0 {
+		return result, nil
+	}
+
+	day := envelope.Days[0]
+
+	result.Date, err = normalizeDate(day.Time)
+	if err != nil {
+		return result, fmt.Errorf("invalid ecb snapshot: %w", err)
+	}
+
+	for idx, entry := range day.Rates {
+		position := xml.Position{Index: idx, Element: "Cube", Line: 0, Column: 0}
+
+		rate, err := decimal.Parse(entry.Rate)
+		if err == nil && rate.IsZero() {
+			err = errZeroRate
+		}
+
+		if err != nil {
+			err = collector.Reject(position, entry.Currency, "rate", err)
+			if err != nil {
+				return result, err //nolint:wrapcheck
+			}
+
+			continue
+		}
+
+		value, _ := decimal.FromInt(1).Div(rate, ecbPlaces)
+
+		raw := currency.RawCurrency{
+			ID:        "",
+			RecordID:  "",
+			Date:      "",
+			NumCode:   "",
+			CharCode:  strings.TrimSpace(entry.Currency),
+			Nominal:   "1",
+			Name:      "",
+			Value:     value.TrimZeros(0).String(),
+			VunitRate: "",
+		}
+
+		item, keep, err := collector.Accept(position, &raw)
+		if err != nil {
+			return result, err //nolint:wrapcheck
+		}
+
+		if keep {
+			result.Data = append(result.Data, item)
+		}
+	}
+
+	return result, nil
+}
+
+func init() { //nolint:gochecknoinits
+	Register("ecb", ecbParser{}, Detection{Root: "Envelope", Prefixes: "", Extensions: nil})
+}
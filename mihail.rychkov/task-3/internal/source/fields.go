@@ -0,0 +1,72 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/currency"
+)
+
+const cbrDateLayout = "02.01.2006"
+
+var errBadDate = errors.New("unsupported date")
+
+// canonical maps column and key spellings onto RawCurrency fields, so
+// "char_code", "CharCode", "code" and "currency" all mean the same thing.
+func canonical(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer("_", "", "-", "", " ", "").Replace(name)
+
+	switch name {
+	case "code", "currency":
+		return "charcode"
+	default:
+		return name
+	}
+}
+
+// rawFromFields builds an entry from named text fields. "value" is the price of
+// "nominal" units; a lone "rate" is accepted as the price of a single unit.
+func rawFromFields(fields map[string]string) currency.RawCurrency {
+	value, hasValue := fields["value"]
+	nominal, hasNominal := fields["nominal"]
+
+	if !hasValue {
+		value = fields["rate"]
+	}
+
+	if !hasNominal || !hasValue {
+		nominal = "1"
+	}
+
+	return currency.RawCurrency{
+		ID:        fields["id"],
+		RecordID:  "",
+		Date:      "",
+		NumCode:   fields["numcode"],
+		CharCode:  fields["charcode"],
+		Nominal:   nominal,
+		Name:      fields["name"],
+		Value:     value,
+		VunitRate: fields["vunitrate"],
+	}
+}
+
+// normalizeDate brings ISO dates to the CBR layout used across the snapshots.
+func normalizeDate(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+
+	for _, layout := range []string{cbrDateLayout, time.DateOnly} {
+		day, err := time.Parse(layout, raw)
+		if err == nil {
+			return day.Format(cbrDateLayout), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", errBadDate, raw)
+}
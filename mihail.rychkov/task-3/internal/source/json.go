@@ -0,0 +1,89 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/xml"
+)
+
+type jsonSnapshot struct {
+	Date  string                       `json:"date"`
+	Name  string                       `json:"name"`
+	Base  string                       `json:"base"`
+	Rates []map[string]json.RawMessage `json:"rates"`
+	Data  []map[string]json.RawMessage `json:"data"`
+}
+
+// jsonParser accepts either a bare array of rate objects (as written by the
+// json output format) or an object with date, name, base and a "rates" or
+// "data" array (as stored in the archive). Keys follow the csv column names.
+type jsonParser struct{}
+
+func jsonText(raw json.RawMessage) string {
+	var text string
+
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+
+	return strings.TrimSpace(string(raw))
+}
+
+func (jsonParser) Parse(reader io.Reader, collector *currency.Collector) (currency.Rates, error) {
+	var (
+		result   currency.Rates
+		snapshot jsonSnapshot
+	)
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return result, fmt.Errorf("cannot read json rates: %w", err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &snapshot.Rates)
+	} else {
+		err = json.Unmarshal(data, &snapshot)
+	}
+
+	if err != nil {
+		return result, fmt.Errorf("failed to parse json rates: %w", err)
+	}
+
+	result.Name = snapshot.Name
+	result.Base = strings.ToUpper(snapshot.Base)
+
+	result.Date, err = normalizeDate(snapshot.Date)
+	if err != nil {
+		return result, fmt.Errorf("invalid snapshot date: %w", err)
+	}
+
+	for idx, entry := range append(snapshot.Rates, snapshot.Data...) {
+		fields := make(map[string]string, len(entry))
+		for key, value := range entry {
+			fields[canonical(key)] = jsonText(value)
+		}
+
+		raw := rawFromFields(fields)
+
+		item, keep, err := collector.Accept(xml.Position{Index: idx, Element: "rate", Line: 0, Column: 0}, &raw)
+		if err != nil {
+			return result, err //nolint:wrapcheck
+		}
+
+		if keep {
+			result.Data = append(result.Data, item)
+		}
+	}
+
+	return result, nil
+}
+
+func init() { //nolint:gochecknoinits
+	Register("json", jsonParser{}, Detection{Root: "", Prefixes: "{[", Extensions: []string{".json"}})
+}
@@ -0,0 +1,163 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Rychmick/task-3/internal/currency"
+)
+
+const (
+	AutoFormat = "auto"
+
+	sniffSize = 4096
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown input format")
+	ErrUndetected    = errors.New("cannot detect input format")
+)
+
+// Parser turns one source document into the common rates model. Entries are
+// validated through a currency.Collector, so every parser honours the parse mode.
+type Parser interface {
+	Parse(reader io.Reader, collector *currency.Collector) (currency.Rates, error)
+}
+
+// Detection tells how a format is recognized: by the local name of the XML root
+// element, by the first significant character of the document or by extension.
+type Detection struct {
+	Root       string
+	Prefixes   string
+	Extensions []string
+}
+
+type registration struct {
+	parser    Parser
+	detection Detection
+}
+
+var registry = map[string]registration{} //nolint:gochecknoglobals
+
+func Register(name string, parser Parser, detection Detection) {
+	registry[name] = registration{parser, detection}
+}
+
+func Formats() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func Lookup(name string) (Parser, error) {
+	entry, exists := registry[strings.ToLower(name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownFormat, name, strings.Join(Formats(), ", "))
+	}
+
+	return entry.parser, nil
+}
+
+func rootElement(head []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(head))
+	decoder.Strict = false
+
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return ""
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local
+		}
+	}
+}
+
+func firstSignificant(head []byte) byte {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+
+	trimmed := bytes.TrimLeft(head, " \t\r\n")
+	if len(trimmed) == 0 {
+		return 0
+	}
+
+	return trimmed[0]
+}
+
+// Detect looks at the beginning of the input and at the file name. The reader
+// is not consumed, so it can be handed to the detected parser afterwards.
+func Detect(reader *bufio.Reader, path string) (string, error) {
+	head, err := reader.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return "", fmt.Errorf("cannot read input: %w", err)
+	}
+
+	first := firstSignificant(head)
+
+	var root string
+	if first == '<' {
+		root = rootElement(head)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+
+	var byPrefix, byExt string
+
+	for _, name := range Formats() {
+		detection := registry[name].detection
+
+		switch {
+		case root != "" && detection.Root == root:
+			return name, nil
+		case first != 0 && byPrefix == "" && strings.IndexByte(detection.Prefixes, first) >= 0:
+			byPrefix = name
+		case ext != "" && byExt == "" && slices.Contains(detection.Extensions, ext):
+			byExt = name
+		}
+	}
+
+	switch {
+	case byPrefix != "":
+		return byPrefix, nil
+	case byExt != "":
+		return byExt, nil
+	default:
+		return "", fmt.Errorf("%w: root %q, extension %q", ErrUndetected, root, ext)
+	}
+}
+
+// Decode parses the input with the given format, detecting it when the format is
+// empty or "auto". Bad entries are handled and recorded by the collector.
+func Decode(input io.Reader, format string, path string, collector *currency.Collector) (currency.Rates, error) {
+	reader := bufio.NewReaderSize(input, sniffSize)
+
+	if format == "" || strings.EqualFold(format, AutoFormat) {
+		detected, err := Detect(reader, path)
+		if err != nil {
+			return currency.Rates{}, err
+		}
+
+		format = detected
+	}
+
+	parser, err := Lookup(format)
+	if err != nil {
+		return currency.Rates{}, err
+	}
+
+	return parser.Parse(reader, collector) //nolint:wrapcheck
+}
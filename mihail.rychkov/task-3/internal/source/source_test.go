@@ -0,0 +1,148 @@
+package source_test
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/source"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cbrDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<ValCurs Date="01.03.2024" name="Foreign Currency Market">
+  <Valute ID="R01235"><NumCode>840</NumCode><CharCode>USD</CharCode><Nominal>1</Nominal>` +
+		`<Name>Доллар США</Name><Value>91,2</Value></Valute>
+</ValCurs>`
+
+	ecbDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01"
+    xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+  <gesmes:subject>Reference rates</gesmes:subject>
+  <gesmes:Sender><gesmes:name>European Central Bank</gesmes:name></gesmes:Sender>
+  <Cube>
+    <Cube time="2024-03-01">
+      <Cube currency="USD" rate="1.25"/>
+      <Cube currency="JPY" rate="0"/>
+      <Cube currency="GBP" rate="0.8"/>
+    </Cube>
+    <Cube time="2024-02-29"><Cube currency="USD" rate="1.08"/></Cube>
+  </Cube>
+</gesmes:Envelope>`
+
+	csvDocument = "Date;Base;CharCode;Nominal;Value\n" +
+		"2024-03-01;rub;USD;1;91,2\n" +
+		"2024-03-01;rub;JPY;100;60.5\n"
+
+	jsonDocument = `{"date": "01.03.2024", "base": "USD", "rates": [
+		{"char_code": "EUR", "rate": 1.08},
+		{"char_code": "JPY", "value": "0.67", "nominal": 100, "num_code": 392}
+	]}`
+)
+
+func decode(input io.Reader, format string, path string, mode currency.Mode) (
+	currency.Rates, []currency.Problem, error,
+) {
+	collector := currency.NewCollector(mode)
+
+	rates, err := source.Decode(input, format, path, collector)
+
+	return rates, collector.Problems(), err
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		document string
+		path     string
+		format   string
+	}{
+		{cbrDocument, "", "cbr"},
+		{ecbDocument, "rates.xml", "ecb"},
+		{csvDocument, "rates.CSV", "csv"},
+		{jsonDocument, "", "json"},
+		{"[]", "rates.txt", "json"},
+	}
+
+	for _, testCase := range cases {
+		format, err := source.Detect(bufio.NewReader(strings.NewReader(testCase.document)), testCase.path)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.format, format, testCase.path)
+	}
+
+	_, err := source.Detect(bufio.NewReader(strings.NewReader("USD 91.2")), "rates.txt")
+	require.ErrorIs(t, err, source.ErrUndetected)
+
+	_, err = source.Lookup("bogus")
+	require.ErrorIs(t, err, source.ErrUnknownFormat)
+}
+
+func TestDecodeCBR(t *testing.T) {
+	t.Parallel()
+
+	rates, problems, err := decode(strings.NewReader(cbrDocument), source.AutoFormat, "", currency.ModeStrict)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	assert.Equal(t, "RUB", rates.Base)
+	assert.Equal(t, "01.03.2024", rates.Date)
+	require.Len(t, rates.Data, 1)
+	assert.Equal(t, "91.2", rates.Data[0].Rate.String())
+}
+
+func TestDecodeECB(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := decode(strings.NewReader(ecbDocument), "", "", currency.ModeStrict)
+
+	var parseErr *currency.ParseError
+
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "JPY", parseErr.Problem.CharCode)
+
+	rates, problems, err := decode(strings.NewReader(ecbDocument), "ecb", "", currency.ModeSkip)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "EUR", rates.Base)
+	assert.Equal(t, "01.03.2024", rates.Date)
+	assert.Equal(t, "European Central Bank", rates.Name)
+	require.Len(t, rates.Data, 2)
+	assert.Equal(t, "USD", rates.Data[0].CharCode)
+	assert.Equal(t, "0.8", rates.Data[0].Rate.String())
+	assert.Equal(t, "1.25", rates.Data[1].Rate.String())
+}
+
+func TestDecodeCSV(t *testing.T) {
+	t.Parallel()
+
+	rates, _, err := decode(strings.NewReader(csvDocument), "", "rates.csv", currency.ModeStrict)
+	require.NoError(t, err)
+	assert.Equal(t, "RUB", rates.Base)
+	assert.Equal(t, "01.03.2024", rates.Date)
+	require.Len(t, rates.Data, 2)
+	assert.Equal(t, "0.605", rates.Data[1].Rate.String())
+
+	_, problems, err := decode(strings.NewReader("code,value\nUSD,1\nEUR,x\n"), "csv", "", currency.ModeSkip)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, 3, problems[0].Position.Line)
+
+	_, _, err = decode(strings.NewReader("name,value\nDollar,1\n"), "csv", "", currency.ModeStrict)
+	require.ErrorIs(t, err, source.ErrMissingColumn)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	t.Parallel()
+
+	rates, _, err := decode(strings.NewReader(jsonDocument), "", "", currency.ModeStrict)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", rates.Base)
+	require.Len(t, rates.Data, 2)
+	assert.Equal(t, "1.08", rates.Data[0].Rate.String())
+	assert.Equal(t, uint(392), rates.Data[1].NumCode)
+	assert.Equal(t, "0.0067", rates.Data[1].Rate.String())
+}
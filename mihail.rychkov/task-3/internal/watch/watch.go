@@ -0,0 +1,193 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	DefaultInterval = time.Second
+	DefaultDebounce = time.Millisecond * 500
+)
+
+// Watcher reports changes of a single file. It listens to its directory through
+// fsnotify, so files replaced by rename are noticed too, and falls back to
+// polling where notifications are unavailable, stop working or Poll is set.
+type Watcher struct {
+	Path     string
+	Interval time.Duration
+	Debounce time.Duration
+	Poll     bool
+}
+
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func stat(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{false, 0, time.Time{}}
+	}
+
+	return fileState{true, info.Size(), info.ModTime()}
+}
+
+func (obj fileState) equal(other fileState) bool {
+	return obj.exists == other.exists && obj.size == other.size && obj.modTime.Equal(other.modTime)
+}
+
+func (obj *Watcher) withDefaults() Watcher {
+	result := *obj
+
+	if result.Interval <= 0 {
+		result.Interval = DefaultInterval
+	}
+
+	if result.Debounce <= 0 {
+		result.Debounce = DefaultDebounce
+	}
+
+	return result
+}
+
+func notify(changes chan<- struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+func (obj *Watcher) poll(ctx context.Context, changes chan<- struct{}) error {
+	ticker := time.NewTicker(obj.Interval)
+	defer ticker.Stop()
+
+	last := stat(obj.Path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if current := stat(obj.Path); !current.equal(last) {
+				last = current
+
+				notify(changes)
+			}
+		}
+	}
+}
+
+// events forwards notifications until ctx is done. It reports false when
+// notifications can no longer be trusted: after an error such as a queue
+// overflow, or once the watched directory itself is removed or renamed.
+func (obj *Watcher) events(ctx context.Context, watcher *fsnotify.Watcher, changes chan<- struct{}) bool {
+	target := filepath.Clean(obj.Path)
+	dir := filepath.Dir(target)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return false
+			}
+
+			name := filepath.Clean(event.Name)
+
+			if name == target && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				notify(changes)
+			}
+
+			if name == dir && event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				return false
+			}
+		case <-watcher.Errors:
+			return false
+		}
+	}
+}
+
+// listen keeps watching by polling once notifications fail, reporting a change
+// first since some may have been lost.
+func (obj *Watcher) listen(ctx context.Context, watcher *fsnotify.Watcher, changes chan<- struct{}) error {
+	healthy := obj.events(ctx, watcher, changes)
+
+	watcher.Close()
+
+	if healthy {
+		return nil
+	}
+
+	notify(changes)
+
+	return obj.poll(ctx, changes)
+}
+
+func (obj *Watcher) source(ctx context.Context, changes chan<- struct{}) func() error {
+	if !obj.Poll {
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			err = watcher.Add(filepath.Dir(obj.Path))
+			if err == nil {
+				return func() error { return obj.listen(ctx, watcher, changes) }
+			}
+
+			watcher.Close()
+		}
+	}
+
+	return func() error { return obj.poll(ctx, changes) }
+}
+
+// Run calls action once at start and then after every burst of changes has
+// been quiet for Debounce. It returns when ctx is done.
+func (obj *Watcher) Run(ctx context.Context, action func(ctx context.Context)) error {
+	settings := obj.withDefaults()
+	changes := make(chan struct{}, 1)
+	failed := make(chan error, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	watchFunc := settings.source(ctx, changes)
+
+	go func() { failed <- watchFunc() }()
+
+	action(ctx)
+
+	timer := time.NewTimer(settings.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-failed:
+			if err == nil || errors.Is(err, context.Canceled) {
+				return nil
+			}
+
+			return err
+		case <-changes:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+
+			timer.Reset(settings.Debounce)
+		case <-timer.C:
+			action(ctx)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package watch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rychmick/task-3/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runWatcher(t *testing.T, poll bool) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "input.xml")
+	require.NoError(t, os.WriteFile(path, []byte("first"), 0o600))
+
+	watcher := watch.Watcher{Path: path, Interval: time.Millisecond * 10, Debounce: time.Millisecond * 50, Poll: poll}
+
+	var runs atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- watcher.Run(ctx, func(context.Context) { runs.Add(1) }) }()
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond*5)
+
+	// a burst of writes collapses into a single run
+	for idx := range 5 {
+		require.NoError(t, os.WriteFile(path, []byte("second"+string(rune('0'+idx))), 0o600))
+		time.Sleep(time.Millisecond * 15)
+	}
+
+	require.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, time.Millisecond*5)
+
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(2), runs.Load())
+
+	// replacing the file by rename is noticed as well
+	replacement := path + ".new"
+	require.NoError(t, os.WriteFile(replacement, []byte("third, longer"), 0o600))
+	require.NoError(t, os.Rename(replacement, path))
+
+	require.Eventually(t, func() bool { return runs.Load() == 3 }, time.Second, time.Millisecond*5)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatchNotify(t *testing.T) {
+	t.Parallel()
+
+	runWatcher(t, false)
+}
+
+func TestWatchPoll(t *testing.T) {
+	t.Parallel()
+
+	runWatcher(t, true)
+}
+
+func TestWatchFallsBackToPolling(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "feed")
+	path := filepath.Join(dir, "input.xml")
+
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	require.NoError(t, os.WriteFile(path, []byte("first"), 0o600))
+
+	watcher := watch.Watcher{Path: path, Interval: time.Millisecond * 10, Debounce: time.Millisecond * 20, Poll: false}
+
+	var runs atomic.Int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- watcher.Run(ctx, func(context.Context) { runs.Add(1) }) }()
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond*5)
+
+	// removing the directory ends the notifications, polling takes over
+	require.NoError(t, os.RemoveAll(dir))
+	require.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond*5)
+
+	before := runs.Load()
+
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0o600))
+	require.Eventually(t, func() bool { return runs.Load() > before }, time.Second, time.Millisecond*5)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -1,27 +1,34 @@
 package xml
 
 import (
-	"bytes"
 	"encoding/xml"
 	"fmt"
-	"os"
+	"io"
 
+	"github.com/Rychmick/task-3/internal/compress"
 	"golang.org/x/net/html/charset"
 )
 
-func ParseFile[T any](path string, result *T) error {
-	fileData, err := os.ReadFile(path)
+func Parse[T any](reader io.Reader, result *T) error {
+	decoder := xml.NewDecoder(reader)
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	err := decoder.Decode(result)
 	if err != nil {
-		return fmt.Errorf("cannot read currency list xml file: %w", err)
+		return fmt.Errorf("failed to parse currency list xml: %w", err)
 	}
 
-	decoder := xml.NewDecoder(bytes.NewReader(fileData))
-	decoder.CharsetReader = charset.NewReaderLabel
+	return nil
+}
 
-	err = decoder.Decode(result)
+// ParseFile reads a plain, gzip or zstd compressed file, or an entry of a zip
+// archive given as "archive.zip#entry.xml"; the charset is honoured either way.
+func ParseFile[T any](path string, result *T) error {
+	file, _, err := compress.Open(path)
 	if err != nil {
-		return fmt.Errorf("failed to parse currency list xml file: %w", err)
+		return fmt.Errorf("cannot read currency list xml file: %w", err)
 	}
+	defer file.Close()
 
-	return nil
+	return Parse(file, result)
 }
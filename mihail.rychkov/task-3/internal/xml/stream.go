@@ -0,0 +1,90 @@
+package xml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"golang.org/x/net/html/charset"
+)
+
+// Stream decodes the matching elements one at a time, so memory use does not
+// depend on the number of elements in the document.
+type Stream[T any] struct {
+	decoder *xml.Decoder
+	names   []string
+	root    *xml.StartElement
+	current Position
+}
+
+type Position struct {
+	Index   int
+	Element string
+	Line    int
+	Column  int
+}
+
+func NewStream[T any](reader io.Reader, names ...string) *Stream[T] {
+	decoder := xml.NewDecoder(reader)
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	return &Stream[T]{decoder, names, nil, Position{-1, "", 0, 0}}
+}
+
+// Position describes the element returned by the last Next call: its zero-based
+// index among matching elements and where its start tag ends in the input.
+func (obj *Stream[T]) Position() Position {
+	return obj.current
+}
+
+// Root returns the document element once the first Next call has passed it.
+func (obj *Stream[T]) Root() (xml.StartElement, bool) {
+	if obj.root == nil {
+		return xml.StartElement{}, false
+	}
+
+	return *obj.root, true
+}
+
+// Next decodes the following matching element into result and returns io.EOF at the end of input.
+func (obj *Stream[T]) Next(result *T) error {
+	for {
+		token, err := obj.decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return io.EOF
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read currency list xml: %w", err)
+		}
+
+		start, isStart := token.(xml.StartElement)
+		if !isStart {
+			continue
+		}
+
+		if obj.root == nil {
+			root := start.Copy()
+			obj.root = &root
+
+			continue
+		}
+
+		if !slices.Contains(obj.names, start.Name.Local) {
+			continue
+		}
+
+		line, column := obj.decoder.InputPos()
+		obj.current = Position{obj.current.Index + 1, start.Name.Local, line, column}
+
+		err = obj.decoder.DecodeElement(result, &start)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s element #%d at line %d, column %d: %w",
+				start.Name.Local, obj.current.Index, line, column, err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package xml_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Rychmick/task-3/internal/compress"
+	"github.com/Rychmick/task-3/internal/currency"
+	"github.com/Rychmick/task-3/internal/xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dynamic = "<?xml version=\"1.0\" encoding=\"windows-1251\"?>\n" +
+	"<ValCurs ID=\"R01235\" name=\"\xc4\xe8\xed\xe0\xec\xe8\xea\xe0\">" +
+	"<Record Date=\"02.03.2001\" Id=\"R01235\"><Nominal>1</Nominal><Value>28,6200</Value></Record>" +
+	"<Ignored><Value>1</Value></Ignored>" +
+	"<Record Date=\"03.03.2001\" Id=\"R01235\"><Nominal>10</Nominal><Value>286,5000</Value></Record>" +
+	"</ValCurs>"
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	stream := xml.NewStream[currency.Currency](strings.NewReader(dynamic), "Valute", "Record")
+
+	_, started := stream.Root()
+	assert.False(t, started)
+
+	var items []currency.Currency
+
+	for {
+		var item currency.Currency
+
+		err := stream.Next(&item)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+		require.NoError(t, item.Normalize())
+
+		items = append(items, item)
+	}
+
+	require.Len(t, items, 2)
+	assert.Equal(t, "R01235", items[0].ID)
+	assert.Equal(t, "03.03.2001", items[1].Date)
+	assert.Equal(t, "28.6500", items[1].Rate.String())
+
+	root, started := stream.Root()
+	require.True(t, started)
+	assert.Equal(t, "ValCurs", root.Name.Local)
+	assert.Equal(t, "Динамика", root.Attr[1].Value)
+}
+
+func TestParseFileCompressed(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, name := range []string{"dynamic.xml.gz", "dynamic.xml.zst", "dynamic.zip"} {
+		path := filepath.Join(dir, name)
+
+		file, err := os.Create(path)
+		require.NoError(t, err)
+
+		writer, err := compress.NewWriter(path, file)
+		require.NoError(t, err)
+
+		_, err = io.WriteString(writer, dynamic)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		require.NoError(t, file.Close())
+
+		var rates currency.Rates
+
+		require.NoError(t, xml.ParseFile(path, &rates), name)
+		assert.Equal(t, "Динамика", rates.Name, name)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/Rychmick/task-5/internal/pipeline"
+)
+
+const (
+	exitNodeError  = 1
+	exitUsageError = 2
+	exitIOError    = 3
+	stdStream      = "-"
+)
+
+type listFlag []string
+
+func (obj *listFlag) String() string {
+	return strings.Join(*obj, " ")
+}
+
+func (obj *listFlag) Set(value string) error {
+	*obj = append(*obj, value)
+
+	return nil
+}
+
+func buildDefinition(definitionPath string, capacity int, nodes, sources, sinks listFlag) (pipeline.Definition, error) {
+	var (
+		result pipeline.Definition
+		err    error
+	)
+
+	if definitionPath != "" {
+		result, err = pipeline.ParseFile(definitionPath)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if capacity > 0 {
+		result.Capacity = capacity
+	}
+
+	for _, spec := range nodes {
+		node, err := pipeline.ParseNode(spec)
+		if err != nil {
+			return result, err
+		}
+
+		result.Nodes = append(result.Nodes, node)
+	}
+
+	for _, spec := range sources {
+		endpoint, err := pipeline.ParseEndpoint(spec)
+		if err != nil {
+			return result, err
+		}
+
+		result.Sources = append(result.Sources, endpoint)
+	}
+
+	for _, spec := range sinks {
+		endpoint, err := pipeline.ParseEndpoint(spec)
+		if err != nil {
+			return result, err
+		}
+
+		result.Sinks = append(result.Sinks, endpoint)
+	}
+
+	err = result.Normalize()
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// openFiles are the files behind the streams. Only closing a sink can fail in
+// a way that matters: it may lose output the file had not written yet.
+type openFiles struct {
+	sources []io.Closer
+	sinks   []io.Closer
+}
+
+func (obj *openFiles) close() error {
+	for _, file := range obj.sources {
+		_ = file.Close()
+	}
+
+	var errs []error
+
+	for _, file := range obj.sinks {
+		err := file.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot close sink file: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func openStreams(def pipeline.Definition) (pipeline.Streams, *openFiles, error) {
+	streams := pipeline.Streams{Sources: make(map[string]io.Reader), Sinks: make(map[string]io.Writer)}
+	files := &openFiles{sources: []io.Closer{}, sinks: []io.Closer{}}
+
+	for _, source := range def.Sources {
+		if source.File == stdStream {
+			streams.Sources[source.Channel] = os.Stdin
+
+			continue
+		}
+
+		file, err := os.Open(source.File)
+		if err != nil {
+			return streams, files, fmt.Errorf("cannot open source file: %w", err)
+		}
+
+		files.sources = append(files.sources, file)
+		streams.Sources[source.Channel] = file
+	}
+
+	for _, sink := range def.Sinks {
+		if sink.File == stdStream {
+			streams.Sinks[sink.Channel] = os.Stdout
+
+			continue
+		}
+
+		file, err := os.Create(sink.File)
+		if err != nil {
+			return streams, files, fmt.Errorf("cannot create sink file: %w", err)
+		}
+
+		files.sinks = append(files.sinks, file)
+		streams.Sinks[sink.Channel] = file
+	}
+
+	return streams, files, nil
+}
+
+func run() int {
+	var (
+		definitionPath string
+		capacity       int
+		nodes          listFlag
+		sources        listFlag
+		sinks          listFlag
+	)
+
+	flag.StringVar(&definitionPath, "pipeline", "", "path to pipeline definition yaml file")
+	flag.IntVar(&capacity, "capacity", 0, "capacity of every channel")
+	flag.Var(&nodes, "node", "node as handler:inputs:outputs, may be repeated")
+	flag.Var(&sources, "source", "source as channel[=file], '-' is stdin, may be repeated")
+	flag.Var(&sinks, "sink", "sink as channel[=file], '-' is stdout, may be repeated")
+	flag.Parse()
+
+	def, err := buildDefinition(definitionPath, capacity, nodes, sources, sinks)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		return exitUsageError
+	}
+
+	streams, files, err := openStreams(def)
+	if err != nil {
+		_ = files.close()
+
+		fmt.Fprintln(os.Stderr, err)
+
+		return exitUsageError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = errors.Join(pipeline.Run(ctx, def, streams), files.close())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+
+		switch {
+		case errors.Is(err, pipeline.ErrNodeFailed):
+			return exitNodeError
+		case errors.Is(err, pipeline.ErrBadNode), errors.Is(err, pipeline.ErrUnknownHandler):
+			return exitUsageError
+		default:
+			return exitIOError
+		}
+	}
+
+	return 0
+}
+
+func main() {
+	os.Exit(run())
+}
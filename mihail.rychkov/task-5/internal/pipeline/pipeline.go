@@ -0,0 +1,280 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Rychmick/task-5/pkg/conveyer"
+	"github.com/Rychmick/task-5/pkg/handlers"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultCapacity = 16
+	stdStream       = "-"
+	nodeSpecParts   = 3
+	endpointParts   = 2
+)
+
+var (
+	ErrUnknownHandler = errors.New("unknown handler")
+	ErrBadNode        = errors.New("invalid node definition")
+	ErrBadEndpoint    = errors.New("invalid endpoint definition")
+)
+
+type Node struct {
+	Handler string   `yaml:"handler"`
+	Inputs  []string `yaml:"inputs"`
+	Outputs []string `yaml:"outputs"`
+	Prefix  string   `yaml:"prefix"`
+	Reject  []string `yaml:"reject"`
+	Pattern string   `yaml:"pattern"`
+	Replace string   `yaml:"replace"`
+	Field   string   `yaml:"field"`
+	Every   int      `yaml:"every"`
+	Window  int      `yaml:"window"`
+	Lower   bool     `yaml:"lower"`
+	Cutset  string   `yaml:"cutset"`
+}
+
+type Endpoint struct {
+	Channel string `yaml:"channel"`
+	File    string `yaml:"file"`
+}
+
+type Definition struct {
+	Capacity int        `yaml:"capacity"`
+	Nodes    []Node     `yaml:"nodes"`
+	Sources  []Endpoint `yaml:"sources"`
+	Sinks    []Endpoint `yaml:"sinks"`
+}
+
+func ParseFile(path string) (Definition, error) {
+	var result Definition
+
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		return result, fmt.Errorf("cannot read pipeline file: %w", err)
+	}
+
+	err = yaml.Unmarshal(fileData, &result)
+	if err != nil {
+		return result, fmt.Errorf("failed to parse pipeline file: %w", err)
+	}
+
+	return result, nil
+}
+
+// ParseNode reads the short "handler:in1,in2:out1,out2" form used on the command line.
+func ParseNode(spec string) (Node, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != nodeSpecParts || parts[0] == "" {
+		return Node{}, fmt.Errorf("%w: %q, expected handler:inputs:outputs", ErrBadNode, spec)
+	}
+
+	var result Node
+
+	result.Handler = parts[0]
+	result.Inputs = splitList(parts[1])
+	result.Outputs = splitList(parts[2])
+
+	return result, nil
+}
+
+// ParseEndpoint reads the "channel=file" form, where file "-" stands for stdin or stdout.
+func ParseEndpoint(spec string) (Endpoint, error) {
+	parts := strings.SplitN(spec, "=", endpointParts)
+	if parts[0] == "" {
+		return Endpoint{}, fmt.Errorf("%w: %q, expected channel[=file]", ErrBadEndpoint, spec)
+	}
+
+	if len(parts) == 1 {
+		return Endpoint{parts[0], stdStream}, nil
+	}
+
+	return Endpoint{parts[0], parts[1]}, nil
+}
+
+func splitList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	return strings.Split(raw, ",")
+}
+
+func (obj *Definition) Normalize() error {
+	if obj.Capacity <= 0 {
+		obj.Capacity = defaultCapacity
+	}
+
+	if len(obj.Nodes) == 0 {
+		return fmt.Errorf("%w: pipeline has no nodes", ErrBadNode)
+	}
+
+	heads, tails := obj.endpoints()
+
+	if len(obj.Sources) == 0 && len(heads) > 0 {
+		obj.Sources = []Endpoint{{heads[0], stdStream}}
+	}
+
+	if len(obj.Sinks) == 0 {
+		for _, name := range tails {
+			obj.Sinks = append(obj.Sinks, Endpoint{name, stdStream})
+		}
+	}
+
+	stdinReaders := 0
+
+	for idx := range obj.Sources {
+		if obj.Sources[idx].File == "" {
+			obj.Sources[idx].File = stdStream
+		}
+
+		if obj.Sources[idx].File == stdStream {
+			stdinReaders++
+		}
+	}
+
+	if stdinReaders > 1 {
+		return fmt.Errorf("%w: only one source may read stdin", ErrBadEndpoint)
+	}
+
+	for idx := range obj.Sinks {
+		if obj.Sinks[idx].File == "" {
+			obj.Sinks[idx].File = stdStream
+		}
+	}
+
+	return nil
+}
+
+// endpoints returns channels that are only read by nodes (heads) and only written by nodes (tails).
+func (obj *Definition) endpoints() ([]string, []string) {
+	written := make(map[string]bool)
+	read := make(map[string]bool)
+
+	for _, node := range obj.Nodes {
+		for _, name := range node.Inputs {
+			read[name] = true
+		}
+
+		for _, name := range node.Outputs {
+			written[name] = true
+		}
+	}
+
+	var heads, tails []string
+
+	for _, node := range obj.Nodes {
+		for _, name := range node.Inputs {
+			if !written[name] && !slices.Contains(heads, name) {
+				heads = append(heads, name)
+			}
+		}
+
+		for _, name := range node.Outputs {
+			if !read[name] && !slices.Contains(tails, name) {
+				tails = append(tails, name)
+			}
+		}
+	}
+
+	return heads, tails
+}
+
+func (obj *Definition) Build(conv *conveyer.Conveyer[string]) error {
+	for idx, node := range obj.Nodes {
+		err := register(conv, node)
+		if err != nil {
+			return fmt.Errorf("node #%d (%s): %w", idx, node.Handler, err)
+		}
+	}
+
+	return nil
+}
+
+func register(conv *conveyer.Conveyer[string], node Node) error {
+	switch node.Handler {
+	case "separator":
+		if len(node.Inputs) != 1 || len(node.Outputs) == 0 {
+			return fmt.Errorf("%w: separator needs one input and some outputs", ErrBadNode)
+		}
+
+		conv.RegisterSeparator(handlers.SeparatorFunc, node.Inputs[0], node.Outputs)
+
+		return nil
+	case "multiplexer":
+		if len(node.Inputs) == 0 || len(node.Outputs) != 1 {
+			return fmt.Errorf("%w: multiplexer needs some inputs and one output", ErrBadNode)
+		}
+
+		conv.RegisterMultiplexer(handlers.MultiplexerFunc, node.Inputs, node.Outputs[0])
+
+		return nil
+	}
+
+	if len(node.Inputs) != 1 || len(node.Outputs) != 1 {
+		return fmt.Errorf("%w: %s needs one input and one output", ErrBadNode, node.Handler)
+	}
+
+	decorator, err := decoratorFor(node)
+	if err != nil {
+		return err
+	}
+
+	conv.RegisterDecorator(decorator, node.Inputs[0], node.Outputs[0])
+
+	return nil
+}
+
+func decoratorFor(node Node) (handlers.DecoratorFunc, error) {
+	switch node.Handler {
+	case "prefix":
+		if node.Prefix == "" && node.Reject == nil {
+			return handlers.PrefixDecoratorFunc, nil
+		}
+
+		return handlers.NewPrefixDecorator(node.Prefix, node.Reject, nil), nil
+	case "upper":
+		return handlers.Map(strings.ToUpper), nil
+	case "lower":
+		return handlers.Map(strings.ToLower), nil
+	case "trim":
+		return handlers.Trim(node.Cutset), nil
+	case "normalize":
+		return handlers.Normalize(node.Lower), nil
+	case "regex":
+		pattern, err := regexp.Compile(node.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad pattern: %w", ErrBadNode, err)
+		}
+
+		return handlers.RegexReplace(pattern, node.Replace), nil
+	case "grep":
+		pattern, err := regexp.Compile(node.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad pattern: %w", ErrBadNode, err)
+		}
+
+		return handlers.Filter(pattern.MatchString), nil
+	case "json-field":
+		return handlers.JSONField(node.Field), nil
+	case "sample":
+		if node.Every <= 0 {
+			return nil, fmt.Errorf("%w: sample needs a positive every, got %d", ErrBadNode, node.Every)
+		}
+
+		return handlers.Sample(node.Every), nil
+	case "dedupe":
+		return handlers.Dedupe(node.Window), nil
+	case "words":
+		return handlers.FlatMap(strings.Fields), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownHandler, node.Handler)
+}
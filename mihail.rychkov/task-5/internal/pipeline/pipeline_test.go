@@ -0,0 +1,123 @@
+package pipeline_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Rychmick/task-5/internal/pipeline"
+	"github.com/Rychmick/task-5/pkg/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildDefinition(t *testing.T, specs ...string) pipeline.Definition {
+	t.Helper()
+
+	var def pipeline.Definition
+
+	for _, spec := range specs {
+		node, err := pipeline.ParseNode(spec)
+		require.NoError(t, err)
+
+		def.Nodes = append(def.Nodes, node)
+	}
+
+	require.NoError(t, def.Normalize())
+
+	return def
+}
+
+func TestNormalizeDefaults(t *testing.T) {
+	t.Parallel()
+
+	def := buildDefinition(t, "trim:in:mid", "separator:mid:a,b")
+
+	assert.Equal(t, []pipeline.Endpoint{{Channel: "in", File: "-"}}, def.Sources)
+	assert.Equal(t, []pipeline.Endpoint{{Channel: "a", File: "-"}, {Channel: "b", File: "-"}}, def.Sinks)
+
+	_, err := pipeline.ParseNode("trim:in")
+	require.ErrorIs(t, err, pipeline.ErrBadNode)
+
+	endpoint, err := pipeline.ParseEndpoint("out=result.txt")
+	require.NoError(t, err)
+	assert.Equal(t, pipeline.Endpoint{Channel: "out", File: "result.txt"}, endpoint)
+}
+
+func TestNormalizeRejectsSharedStdin(t *testing.T) {
+	t.Parallel()
+
+	node, err := pipeline.ParseNode("multiplexer:a,b:out")
+	require.NoError(t, err)
+
+	def := pipeline.Definition{
+		Nodes:   []pipeline.Node{node},
+		Sources: []pipeline.Endpoint{{Channel: "a", File: "-"}, {Channel: "b", File: ""}},
+	}
+
+	require.ErrorIs(t, def.Normalize(), pipeline.ErrBadEndpoint)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	def := buildDefinition(t, "normalize:in:mid", "prefix:mid:out")
+
+	var output bytes.Buffer
+
+	streams := pipeline.Streams{
+		Sources: map[string]io.Reader{"in": strings.NewReader(" a  b \nc\n")},
+		Sinks:   map[string]io.Writer{"out": &output},
+	}
+
+	err := pipeline.Run(context.Background(), def, streams)
+	require.NoError(t, err)
+	assert.Equal(t, "decorated: a b\ndecorated: c\n", output.String())
+}
+
+func TestRunNodeError(t *testing.T) {
+	t.Parallel()
+
+	def := buildDefinition(t, "prefix:in:out")
+
+	streams := pipeline.Streams{
+		Sources: map[string]io.Reader{"in": strings.NewReader("no decorator\n")},
+		Sinks:   map[string]io.Writer{"out": io.Discard},
+	}
+
+	err := pipeline.Run(context.Background(), def, streams)
+	require.ErrorIs(t, err, pipeline.ErrNodeFailed)
+	require.ErrorIs(t, err, handlers.ErrNoDecorator)
+
+	def = buildDefinition(t, "missing:in:out")
+
+	err = pipeline.Run(context.Background(), def, streams)
+	require.ErrorIs(t, err, pipeline.ErrUnknownHandler)
+}
+
+func TestRunInputLargerThanCapacity(t *testing.T) {
+	t.Parallel()
+
+	def := buildDefinition(t, "trim:in:out")
+	def.Capacity = 2
+
+	var input, expected strings.Builder
+
+	for idx := range 1000 {
+		fmt.Fprintf(&input, " %d \n", idx)
+		fmt.Fprintf(&expected, "%d\n", idx)
+	}
+
+	var output bytes.Buffer
+
+	streams := pipeline.Streams{
+		Sources: map[string]io.Reader{"in": strings.NewReader(input.String())},
+		Sinks:   map[string]io.Writer{"out": &output},
+	}
+
+	require.NoError(t, pipeline.Run(context.Background(), def, streams))
+	assert.Equal(t, expected.String(), output.String())
+}
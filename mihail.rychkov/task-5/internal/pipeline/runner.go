@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"sync"
+
+	"github.com/Rychmick/task-5/pkg/conveyer"
+)
+
+var ErrNodeFailed = errors.New("pipeline node failed")
+
+type Streams struct {
+	Sources map[string]io.Reader
+	Sinks   map[string]io.Writer
+}
+
+func feed(ctx context.Context, conv *conveyer.Conveyer[string], channel string, source io.Reader) error {
+	scanner := bufio.NewScanner(source)
+
+	for scanner.Scan() {
+		err := conv.SendContext(ctx, channel, scanner.Text())
+		if err != nil {
+			return fmt.Errorf("cannot feed %q: %w", channel, err)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return fmt.Errorf("cannot read input for %q: %w", channel, err)
+	}
+
+	return nil
+}
+
+func drain(conv *conveyer.Conveyer[string], channel string, sink io.Writer) error {
+	for {
+		str, err := conv.Recv(channel)
+		if errors.Is(err, conveyer.ErrClosedChanelEmpty) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("cannot read %q: %w", channel, err)
+		}
+
+		_, err = fmt.Fprintln(sink, str)
+		if err != nil {
+			return fmt.Errorf("cannot write output of %q: %w", channel, err)
+		}
+	}
+}
+
+// Run feeds the sources into the pipeline once its nodes are running. When a
+// source ends its channel is closed, the nodes drain and close their outputs
+// in turn, and Run returns after the sinks have written everything.
+func Run(ctx context.Context, def Definition, streams Streams) error {
+	conv := conveyer.NewConveyer[string](def.Capacity)
+
+	err := def.Build(&conv)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runResult := make(chan error, 1)
+
+	go func() {
+		runResult <- conv.Run(ctx)
+
+		cancel()
+	}()
+
+	var (
+		group    sync.WaitGroup
+		errMutex sync.Mutex
+		ioErrors []error
+	)
+
+	collect := func(err error) {
+		if err != nil {
+			errMutex.Lock()
+			ioErrors = append(ioErrors, err)
+			errMutex.Unlock()
+		}
+	}
+
+	heads, tails := def.endpoints()
+
+	sinks := maps.Clone(streams.Sinks)
+	if sinks == nil {
+		sinks = make(map[string]io.Writer)
+	}
+
+	for _, name := range tails {
+		if _, found := sinks[name]; !found {
+			sinks[name] = io.Discard
+		}
+	}
+
+	for channel, sink := range sinks {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			collect(drain(&conv, channel, sink))
+		}()
+	}
+
+	<-conv.Started()
+
+	for _, name := range heads {
+		if _, found := streams.Sources[name]; !found {
+			_ = conv.CloseInput(name)
+		}
+	}
+
+	for channel, source := range streams.Sources {
+		group.Add(1)
+
+		go func() {
+			defer group.Done()
+
+			err := feed(ctx, &conv, channel, source)
+			if !errors.Is(err, conveyer.ErrConveyerClosed) {
+				collect(err)
+			}
+
+			_ = conv.CloseInput(channel)
+		}()
+	}
+
+	runErr := <-runResult
+
+	group.Wait()
+
+	if runErr != nil {
+		return fmt.Errorf("%w: %w", ErrNodeFailed, runErr)
+	}
+
+	return errors.Join(ioErrors...)
+}
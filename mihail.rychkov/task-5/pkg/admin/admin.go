@@ -0,0 +1,164 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Rychmick/task-5/pkg/conveyer"
+)
+
+type Handler[T any] struct {
+	conv *conveyer.Conveyer[T]
+	mux  *http.ServeMux
+}
+
+func NewHandler[T any](conv *conveyer.Conveyer[T]) *Handler[T] {
+	handler := &Handler[T]{conv, http.NewServeMux()}
+
+	handler.mux.HandleFunc("GET /nodes", handler.listNodes)
+	handler.mux.HandleFunc("POST /nodes/{name}/pause", handler.pauseNode)
+	handler.mux.HandleFunc("POST /nodes/{name}/resume", handler.resumeNode)
+	handler.mux.HandleFunc("POST /nodes/{name}/step", handler.stepNode)
+	handler.mux.HandleFunc("POST /pause", handler.pause)
+	handler.mux.HandleFunc("POST /resume", handler.resume)
+	handler.mux.HandleFunc("GET /channels", handler.listChannels)
+	handler.mux.HandleFunc("GET /channels/{name}/messages", handler.peekMessages)
+	handler.mux.HandleFunc("POST /channels/{name}/messages", handler.injectMessage)
+	handler.mux.HandleFunc("POST /stop", handler.stop)
+
+	return handler
+}
+
+func (obj *Handler[T]) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	obj.mux.ServeHTTP(writer, request)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(writer http.ResponseWriter, status int, data any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+
+	_ = json.NewEncoder(writer).Encode(data)
+}
+
+func writeError(writer http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, conveyer.ErrChannelNotFound), errors.Is(err, conveyer.ErrNodeNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, conveyer.ErrConveyerClosed), errors.Is(err, conveyer.ErrNodeNotPaused),
+		errors.Is(err, conveyer.ErrConveyerNotPaused), errors.Is(err, conveyer.ErrChannelBusy):
+		status = http.StatusConflict
+	}
+
+	writeJSON(writer, status, errorResponse{err.Error()})
+}
+
+func (obj *Handler[T]) listNodes(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, obj.conv.Nodes())
+}
+
+func (obj *Handler[T]) pauseNode(writer http.ResponseWriter, request *http.Request) {
+	err := obj.conv.PauseNode(request.PathValue("name"))
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (obj *Handler[T]) resumeNode(writer http.ResponseWriter, request *http.Request) {
+	err := obj.conv.ResumeNode(request.PathValue("name"))
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (obj *Handler[T]) stepNode(writer http.ResponseWriter, request *http.Request) {
+	err := obj.conv.Step(request.PathValue("name"))
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (obj *Handler[T]) pause(writer http.ResponseWriter, _ *http.Request) {
+	obj.conv.Pause()
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (obj *Handler[T]) resume(writer http.ResponseWriter, _ *http.Request) {
+	obj.conv.Resume()
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (obj *Handler[T]) listChannels(writer http.ResponseWriter, _ *http.Request) {
+	writeJSON(writer, http.StatusOK, obj.conv.Channels())
+}
+
+func (obj *Handler[T]) peekMessages(writer http.ResponseWriter, request *http.Request) {
+	var limit int
+
+	if raw := request.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			writeJSON(writer, http.StatusBadRequest, errorResponse{"limit must be a non-negative integer"})
+
+			return
+		}
+
+		limit = parsed
+	}
+
+	messages, err := obj.conv.Peek(request.PathValue("name"), limit)
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writeJSON(writer, http.StatusOK, messages)
+}
+
+func (obj *Handler[T]) injectMessage(writer http.ResponseWriter, request *http.Request) {
+	var message T
+
+	err := json.NewDecoder(request.Body).Decode(&message)
+	if err != nil {
+		writeJSON(writer, http.StatusBadRequest, errorResponse{"invalid message: " + err.Error()})
+
+		return
+	}
+
+	err = obj.conv.SendContext(request.Context(), request.PathValue("name"), message)
+	if err != nil {
+		writeError(writer, err)
+
+		return
+	}
+
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+func (obj *Handler[T]) stop(writer http.ResponseWriter, _ *http.Request) {
+	obj.conv.Stop()
+
+	writer.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,185 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rychmick/task-5/pkg/admin"
+	"github.com/Rychmick/task-5/pkg/conveyer"
+	"github.com/Rychmick/task-5/pkg/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doRequest(t *testing.T, handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func decodeBody[R any](t *testing.T, recorder *httptest.ResponseRecorder) R {
+	t.Helper()
+
+	var result R
+
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+
+	return result
+}
+
+func TestListAndInject(t *testing.T) {
+	t.Parallel()
+
+	conv := conveyer.New(5)
+	conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	handler := admin.NewHandler(&conv.Conveyer)
+
+	nodes := decodeBody[[]conveyer.NodeInfo](t, doRequest(t, handler, http.MethodGet, "/nodes", ""))
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "decorator-0", nodes[0].Name)
+	assert.Equal(t, conveyer.NodeIdle, nodes[0].State)
+
+	recorder := doRequest(t, handler, http.MethodPost, "/channels/in/messages", `"hello"`)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	recorder = doRequest(t, handler, http.MethodGet, "/channels/in/messages", "")
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	channels := decodeBody[[]conveyer.ChannelInfo](t, doRequest(t, handler, http.MethodGet, "/channels", ""))
+	require.Len(t, channels, 2)
+	assert.Equal(t, conveyer.ChannelInfo{Name: "in", Length: 1, Capacity: 5, Closed: false}, channels[0])
+
+	recorder = doRequest(t, handler, http.MethodPost, "/channels/missing/messages", `"hello"`)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/nodes/missing/pause", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/nodes/decorator-0/step", "")
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/pause", "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.True(t, conv.Paused())
+
+	peeked := decodeBody[[]string](t, doRequest(t, handler, http.MethodGet, "/channels/in/messages", ""))
+	assert.Equal(t, []string{"hello"}, peeked)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/nodes/decorator-0/step", "")
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/resume", "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.False(t, conv.Paused())
+}
+
+func TestPauseResumeStop(t *testing.T) {
+	t.Parallel()
+
+	conv := conveyer.New(5)
+	conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	handler := admin.NewHandler(&conv.Conveyer)
+
+	recorder := doRequest(t, handler, http.MethodPost, "/nodes/decorator-0/pause", "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancelFunc()
+
+	done := make(chan error, 1)
+
+	go func() { done <- conv.Run(ctx) }()
+
+	require.NoError(t, conv.Send("in", "1"))
+
+	require.Eventually(t, func() bool {
+		nodes := decodeBody[[]conveyer.NodeInfo](t, doRequest(t, handler, http.MethodGet, "/nodes", ""))
+
+		return nodes[0].State == conveyer.NodePaused
+	}, time.Second, time.Millisecond*10)
+
+	recorder = doRequest(t, handler, http.MethodGet, "/channels/in/messages", "")
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/pause", "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	peeked := decodeBody[[]string](t, doRequest(t, handler, http.MethodGet, "/channels/in/messages?limit=1", ""))
+	assert.Equal(t, []string{"1"}, peeked)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/resume", "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/nodes/decorator-0/resume", "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	res, err := conv.Recv("out")
+	require.NoError(t, err)
+	assert.Equal(t, "decorated: 1", res)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/stop", "")
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	require.NoError(t, <-done)
+
+	nodes := decodeBody[[]conveyer.NodeInfo](t, doRequest(t, handler, http.MethodGet, "/nodes", ""))
+	assert.Equal(t, conveyer.NodeFinished, nodes[0].State)
+
+	recorder = doRequest(t, handler, http.MethodPost, "/channels/in/messages", `"late"`)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestStopWhileInjectBlocked(t *testing.T) {
+	t.Parallel()
+
+	conv := conveyer.New(1)
+	conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+	conv.Pause()
+
+	handler := admin.NewHandler(&conv.Conveyer)
+
+	done := make(chan error, 1)
+
+	go func() { done <- conv.Run(context.Background()) }()
+
+	<-conv.Started()
+	require.NoError(t, conv.Send("in", "1"))
+
+	injected := make(chan int, 1)
+
+	go func() {
+		injected <- doRequest(t, handler, http.MethodPost, "/channels/in/messages", `"2"`).Code
+	}()
+
+	stopped := make(chan int, 1)
+
+	go func() { stopped <- doRequest(t, handler, http.MethodPost, "/stop", "").Code }()
+
+	select {
+	case code := <-stopped:
+		assert.Equal(t, http.StatusAccepted, code)
+	case <-time.After(time.Second):
+		t.Fatal("stop waited for a blocked inject")
+	}
+
+	require.NoError(t, <-done)
+
+	select {
+	case code := <-injected:
+		assert.NotEqual(t, http.StatusAccepted, code)
+	case <-time.After(time.Second):
+		t.Fatal("blocked inject was not released by stop")
+	}
+}
@@ -0,0 +1,387 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	DecoratorFunc   func(ctx context.Context, input chan string, output chan string) error
+	SeparatorFunc   func(ctx context.Context, input chan string, outputs []chan string) error
+	MultiplexerFunc func(ctx context.Context, inputs []chan string, output chan string) error
+)
+
+const (
+	defaultTimeout  = time.Second
+	defaultMessages = 12
+	fanWidth        = 3
+	leakPoll        = time.Millisecond * 10
+	blockDelay      = time.Millisecond * 50
+)
+
+var (
+	ErrDiverged = errors.New("implementation diverges from reference behaviour")
+	errTimeout  = errors.New("handler did not return in time")
+)
+
+// Options tune the checks. Decoration is the prefix a decorator adds to every
+// message; it is stripped before received messages are compared with sent ones.
+type Options struct {
+	Timeout      time.Duration
+	Messages     int
+	EmptyListErr error
+	CheckLeaks   bool
+	Decoration   string
+}
+
+type Divergence struct {
+	Check   string
+	Details string
+}
+
+func (obj Divergence) String() string {
+	return obj.Check + ": " + obj.Details
+}
+
+type Report struct {
+	Divergences []Divergence
+}
+
+func (obj *Report) add(check string, format string, args ...any) {
+	obj.Divergences = append(obj.Divergences, Divergence{check, fmt.Sprintf(format, args...)})
+}
+
+func (obj *Report) Err() error {
+	if len(obj.Divergences) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(obj.Divergences))
+	for _, divergence := range obj.Divergences {
+		lines = append(lines, divergence.String())
+	}
+
+	return fmt.Errorf("%w:\n%s", ErrDiverged, strings.Join(lines, "\n"))
+}
+
+func (obj Options) withDefaults() Options {
+	if obj.Timeout <= 0 {
+		obj.Timeout = defaultTimeout
+	}
+
+	if obj.Messages <= 0 {
+		obj.Messages = defaultMessages
+	}
+
+	return obj
+}
+
+func messages(count int) []string {
+	result := make([]string, count)
+	for idx := range result {
+		result[idx] = fmt.Sprintf("message-%03d", idx)
+	}
+
+	return result
+}
+
+func invoke(ctx context.Context, timeout time.Duration, call func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+
+	go func() { done <- call(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return errTimeout
+	}
+}
+
+// cancelWhileBlocked starts the handler, lets it block, then cancels it and expects a prompt nil return.
+func cancelWhileBlocked(report *Report, check string, opts Options, call func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- call(ctx) }()
+
+	select {
+	case err := <-done:
+		cancel()
+		report.add(check, "returned before cancellation: %v", err)
+
+		return
+	case <-time.After(blockDelay):
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			report.add(check, "returned %v instead of nil after cancellation", err)
+		}
+	case <-time.After(opts.Timeout):
+		report.add(check, "%v", errTimeout)
+	}
+}
+
+func checkLeaks(report *Report, opts Options, baseline int) {
+	if !opts.CheckLeaks {
+		return
+	}
+
+	deadline := time.Now().Add(opts.Timeout)
+
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			report.add("goroutine leak", "%d goroutines still running, expected at most %d",
+				runtime.NumGoroutine(), baseline)
+
+			return
+		}
+
+		time.Sleep(leakPoll)
+	}
+}
+
+func collect(channel chan string) []string {
+	result := make([]string, 0, len(channel))
+
+	for len(channel) > 0 {
+		result = append(result, <-channel)
+	}
+
+	return result
+}
+
+func isSubsequence(sent []string, received []string) bool {
+	pos := 0
+
+	for _, str := range received {
+		for pos < len(sent) && str != sent[pos] {
+			pos++
+		}
+
+		if pos == len(sent) {
+			return false
+		}
+
+		pos++
+	}
+
+	return true
+}
+
+// undecorate strips the expected decoration and returns the first message
+// that lacks it.
+func undecorate(received []string, decoration string) ([]string, string, bool) {
+	result := make([]string, 0, len(received))
+
+	for _, str := range received {
+		stripped, found := strings.CutPrefix(str, decoration)
+		if !found {
+			return nil, str, false
+		}
+
+		result = append(result, stripped)
+	}
+
+	return result, "", true
+}
+
+func CheckDecorator(decorator DecoratorFunc, opts Options) *Report {
+	opts = opts.withDefaults()
+	report := &Report{nil}
+	baseline := runtime.NumGoroutine()
+
+	sent := messages(opts.Messages)
+	input := make(chan string, len(sent))
+	output := make(chan string, len(sent))
+
+	for _, str := range sent {
+		input <- str
+	}
+
+	close(input)
+
+	err := invoke(context.Background(), opts.Timeout, func(ctx context.Context) error {
+		return decorator(ctx, input, output)
+	})
+	if err != nil {
+		report.add("close propagation", "closed input did not end the handler cleanly: %v", err)
+	}
+
+	received := collect(output)
+
+	stripped, bare, decorated := undecorate(received, opts.Decoration)
+	if !decorated {
+		report.add("decoration", "received %q without %q", bare, opts.Decoration)
+	} else if len(stripped) != len(sent) || !isSubsequence(sent, stripped) {
+		report.add("ordering", "sent %d messages, received %v", len(sent), received)
+	}
+
+	blockedInput := make(chan string, 1)
+	blockedInput <- sent[0]
+
+	cancelWhileBlocked(report, "cancel on send", opts, func(ctx context.Context) error {
+		return decorator(ctx, blockedInput, make(chan string))
+	})
+
+	cancelWhileBlocked(report, "cancel on receive", opts, func(ctx context.Context) error {
+		return decorator(ctx, make(chan string), make(chan string))
+	})
+
+	checkLeaks(report, opts, baseline)
+
+	return report
+}
+
+func checkEmptyList(report *Report, opts Options, call func(ctx context.Context) error) {
+	err := invoke(context.Background(), opts.Timeout, call)
+
+	switch {
+	case errors.Is(err, errTimeout):
+		report.add("empty channel list", "%v", err)
+	case err == nil:
+		report.add("empty channel list", "expected an error, got nil")
+	case opts.EmptyListErr != nil && !errors.Is(err, opts.EmptyListErr):
+		report.add("empty channel list", "expected %v, got %v", opts.EmptyListErr, err)
+	}
+}
+
+func CheckSeparator(separator SeparatorFunc, opts Options) *Report {
+	opts = opts.withDefaults()
+	report := &Report{nil}
+	baseline := runtime.NumGoroutine()
+
+	checkEmptyList(report, opts, func(ctx context.Context) error {
+		return separator(ctx, make(chan string), nil)
+	})
+
+	sent := messages(opts.Messages)
+	input := make(chan string, len(sent))
+	outputs := make([]chan string, fanWidth)
+
+	for idx := range outputs {
+		outputs[idx] = make(chan string, len(sent))
+	}
+
+	for _, str := range sent {
+		input <- str
+	}
+
+	close(input)
+
+	err := invoke(context.Background(), opts.Timeout, func(ctx context.Context) error {
+		return separator(ctx, input, outputs)
+	})
+	if err != nil {
+		report.add("close propagation", "closed input did not end the handler cleanly: %v", err)
+	}
+
+	var total int
+
+	for idx, output := range outputs {
+		received := collect(output)
+		total += len(received)
+
+		if !isSubsequence(sent, received) {
+			report.add("ordering", "output #%d received %v out of order", idx, received)
+		}
+	}
+
+	if total != len(sent) {
+		report.add("ordering", "sent %d messages, outputs received %d", len(sent), total)
+	}
+
+	blockedInput := make(chan string, 1)
+	blockedInput <- sent[0]
+
+	cancelWhileBlocked(report, "cancel on send", opts, func(ctx context.Context) error {
+		return separator(ctx, blockedInput, []chan string{make(chan string)})
+	})
+
+	cancelWhileBlocked(report, "cancel on receive", opts, func(ctx context.Context) error {
+		return separator(ctx, make(chan string), []chan string{make(chan string)})
+	})
+
+	checkLeaks(report, opts, baseline)
+
+	return report
+}
+
+func CheckMultiplexer(multiplexer MultiplexerFunc, opts Options) *Report {
+	opts = opts.withDefaults()
+	report := &Report{nil}
+	baseline := runtime.NumGoroutine()
+
+	checkEmptyList(report, opts, func(ctx context.Context) error {
+		return multiplexer(ctx, nil, make(chan string))
+	})
+
+	sent := messages(opts.Messages)
+	inputs := make([]chan string, fanWidth)
+	perInput := make([][]string, fanWidth)
+
+	for idx := range inputs {
+		inputs[idx] = make(chan string, len(sent))
+	}
+
+	for idx, str := range sent {
+		inputs[idx%fanWidth] <- str
+		perInput[idx%fanWidth] = append(perInput[idx%fanWidth], str)
+	}
+
+	for _, input := range inputs {
+		close(input)
+	}
+
+	output := make(chan string, len(sent))
+
+	err := invoke(context.Background(), opts.Timeout, func(ctx context.Context) error {
+		return multiplexer(ctx, inputs, output)
+	})
+	if err != nil {
+		report.add("close propagation", "closed inputs did not end the handler cleanly: %v", err)
+	}
+
+	received := collect(output)
+	if len(received) != len(sent) {
+		report.add("ordering", "sent %d messages, received %d", len(sent), len(received))
+	}
+
+	for idx, expected := range perInput {
+		fromInput := make([]string, 0, len(expected))
+
+		for _, str := range received {
+			if slices.Contains(expected, str) {
+				fromInput = append(fromInput, str)
+			}
+		}
+
+		if !isSubsequence(expected, fromInput) {
+			report.add("ordering", "messages of input #%d arrived out of order: %v", idx, fromInput)
+		}
+	}
+
+	blockedInput := make(chan string, 1)
+	blockedInput <- sent[0]
+
+	cancelWhileBlocked(report, "cancel on send", opts, func(ctx context.Context) error {
+		return multiplexer(ctx, []chan string{blockedInput}, make(chan string))
+	})
+
+	cancelWhileBlocked(report, "cancel on receive", opts, func(ctx context.Context) error {
+		return multiplexer(ctx, []chan string{make(chan string)}, make(chan string))
+	})
+
+	checkLeaks(report, opts, baseline)
+
+	return report
+}
@@ -0,0 +1,71 @@
+package conformance_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Rychmick/task-5/pkg/conformance"
+	"github.com/Rychmick/task-5/pkg/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sample(every int) conformance.DecoratorFunc {
+	return conformance.DecoratorFunc(handlers.Sample(every))
+}
+
+//nolint:paralleltest // goroutine counting needs an otherwise idle process
+func TestReferenceHandlers(t *testing.T) {
+	opts := conformance.Options{EmptyListErr: handlers.ErrEmptyChannelList, CheckLeaks: true}
+
+	decorated := opts
+	decorated.Decoration = "decorated: "
+
+	require.NoError(t, conformance.CheckDecorator(handlers.PrefixDecoratorFunc, decorated).Err())
+	require.NoError(t, conformance.CheckDecorator(conformance.DecoratorFunc(handlers.Trim("")), opts).Err())
+	require.NoError(t, conformance.CheckSeparator(handlers.SeparatorFunc, opts).Err())
+	require.NoError(t, conformance.CheckMultiplexer(handlers.MultiplexerFunc, opts).Err())
+}
+
+func TestReportsDivergences(t *testing.T) {
+	t.Parallel()
+
+	dropping := conformance.DecoratorFunc(func(ctx context.Context, input chan string, _ chan string) error {
+		for {
+			select {
+			case _, ok := <-input:
+				if !ok {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+
+	report := conformance.CheckDecorator(dropping, conformance.Options{})
+	require.ErrorIs(t, report.Err(), conformance.ErrDiverged)
+
+	checks := make([]string, 0, len(report.Divergences))
+	for _, divergence := range report.Divergences {
+		checks = append(checks, divergence.Check)
+	}
+
+	assert.Equal(t, []string{"ordering", "cancel on send", "cancel on receive"}, checks)
+
+	report = conformance.CheckDecorator(sample(1), conformance.Options{})
+	require.NoError(t, report.Err())
+
+	report = conformance.CheckDecorator(handlers.PrefixDecoratorFunc, conformance.Options{Decoration: "prefixed: "})
+	assert.Equal(t, "decoration", report.Divergences[0].Check)
+
+	suffixed := handlers.Map(func(str string) string { return str + "0" })
+
+	report = conformance.CheckDecorator(conformance.DecoratorFunc(suffixed), conformance.Options{})
+	assert.Equal(t, "ordering", report.Divergences[0].Check, "message-001 must not match message-0010")
+
+	report = conformance.CheckSeparator(
+		func(_ context.Context, _ chan string, _ []chan string) error { return nil },
+		conformance.Options{})
+	assert.Equal(t, "empty channel list", report.Divergences[0].Check)
+}
@@ -9,52 +9,222 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// pipe is a named channel. Senders are tracked while they wait, so the channel
+// is only closed once none of them can still write to it. Writers counts the
+// nodes sending to it: the channel is closed when the last one finishes.
+// Every send and receive holds traffic shared; Peek holds it exclusively.
+type pipe[T any] struct {
+	channel chan T
+	writers int
+	closed  bool
+	senders sync.WaitGroup
+	traffic sync.RWMutex
+}
+
 type Conveyer[T any] struct {
 	channelCapacity int
-	pipes           map[string]chan T
-	nodes           []func(c context.Context) error
+	pipes           map[string]*pipe[T]
+	nodes           []*node[T]
 	mutex           sync.RWMutex
+	control         sync.Mutex
+	cancel          context.CancelFunc
+	stopRequested   bool
+	closed          bool
+	started         chan struct{}
+	stopped         chan struct{}
+	gate            *gate
 }
 
 var (
 	ErrChannelNotFound   = errors.New("chan not found")
 	ErrClosedChanelEmpty = errors.New("requested channel was closed and is empty")
+	ErrNodeNotFound      = errors.New("node not found")
+	ErrConveyerClosed    = errors.New("conveyer channels are closed")
+	ErrNodeNotPaused     = errors.New("node is not paused")
+	ErrConveyerNotPaused = errors.New("conveyer is not paused")
+	ErrChannelBusy       = errors.New("channel has a pending send or receive")
 )
 
 func NewConveyer[T any](channelCapacity int) Conveyer[T] {
-	return Conveyer[T]{channelCapacity, make(map[string]chan T), []func(ctx context.Context) error{}, sync.RWMutex{}}
+	return Conveyer[T]{
+		channelCapacity,
+		make(map[string]*pipe[T]),
+		[]*node[T]{},
+		sync.RWMutex{},
+		sync.Mutex{},
+		nil,
+		false,
+		false,
+		make(chan struct{}),
+		make(chan struct{}),
+		newGate(),
+	}
 }
 
 func (obj *Conveyer[T]) reserveChannel(name string) chan T {
-	channel, exists := obj.pipes[name]
+	current, exists := obj.pipes[name]
 	if exists {
-		return channel
+		return current.channel
 	}
 
-	channel = make(chan T, obj.channelCapacity)
-	obj.pipes[name] = channel
+	current = &pipe[T]{make(chan T, obj.channelCapacity), 0, false, sync.WaitGroup{}, sync.RWMutex{}}
+	obj.pipes[name] = current
 
-	return channel
+	return current.channel
 }
 
-func (obj *Conveyer[T]) Run(ctx context.Context) error {
-	defer func() {
-		obj.mutex.RLock()
-		defer obj.mutex.RUnlock()
+// acquire finds a channel for sending and registers the sender; the caller
+// must call senders.Done once it no longer touches the channel.
+func (obj *Conveyer[T]) acquire(name string) (*pipe[T], error) {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
 
-		for _, channel := range obj.pipes {
-			close(channel)
+	current, exists := obj.pipes[name]
+	if !exists {
+		return nil, ErrChannelNotFound
+	}
+
+	if current.closed {
+		return nil, ErrConveyerClosed
+	}
+
+	current.senders.Add(1)
+
+	return current, nil
+}
+
+// closeAll marks every channel closed, then closes each one after its pending
+// senders, which are woken by the stopped channel, have given up.
+func (obj *Conveyer[T]) closeAll() {
+	obj.mutex.Lock()
+
+	pipes := make([]*pipe[T], 0, len(obj.pipes))
+
+	for _, current := range obj.pipes {
+		if !current.closed {
+			current.closed = true
+			pipes = append(pipes, current)
 		}
-	}()
+	}
+
+	obj.closed = true
+
+	obj.mutex.Unlock()
+
+	for _, current := range pipes {
+		current.senders.Wait()
+		close(current.channel)
+	}
+}
+
+// closePipe closes one channel once its pending senders are done.
+func (obj *Conveyer[T]) closePipe(current *pipe[T]) {
+	obj.mutex.Lock()
+
+	if current.closed {
+		obj.mutex.Unlock()
+
+		return
+	}
 
+	current.closed = true
+
+	obj.mutex.Unlock()
+
+	current.senders.Wait()
+	close(current.channel)
+}
+
+// finishWriter closes the outputs of a finished node that no other node writes to.
+func (obj *Conveyer[T]) finishWriter(outputs []string) {
+	var finished []*pipe[T]
+
+	obj.mutex.Lock()
+
+	for _, name := range outputs {
+		current := obj.pipes[name]
+
+		current.writers--
+		if current.writers == 0 {
+			finished = append(finished, current)
+		}
+	}
+
+	obj.mutex.Unlock()
+
+	for _, current := range finished {
+		obj.closePipe(current)
+	}
+}
+
+// CloseInput tells the conveyer that nothing more will be sent to the channel
+// from outside. A channel no node writes to is closed right away, so the nodes
+// reading it finish once it drains and close their own outputs in turn; other
+// channels are closed when their last writer finishes anyway.
+func (obj *Conveyer[T]) CloseInput(name string) error {
 	obj.mutex.RLock()
+	current, exists := obj.pipes[name]
+	external := exists && current.writers == 0
+	obj.mutex.RUnlock()
+
+	if !exists {
+		return ErrChannelNotFound
+	}
+
+	if external {
+		obj.closePipe(current)
+	}
+
+	return nil
+}
+
+// Started is closed once Run has started every node.
+func (obj *Conveyer[T]) Started() <-chan struct{} {
+	return obj.started
+}
+
+func (obj *Conveyer[T]) Run(ctx context.Context) error {
+	defer func() {
+		close(obj.stopped)
+		obj.closeAll()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	obj.control.Lock()
+
+	obj.cancel = cancel
+	if obj.stopRequested {
+		cancel()
+	}
+
+	obj.control.Unlock()
+
+	obj.mutex.Lock()
 
 	group, ctx := errgroup.WithContext(ctx)
-	for _, functor := range obj.nodes {
-		group.Go(func() error { return functor(ctx) })
+	for _, current := range obj.nodes {
+		pipes := make([]*pipe[T], len(current.inputs))
+		for idx, name := range current.inputs {
+			pipes[idx] = obj.pipes[name]
+		}
+
+		sinks := make([]*pipe[T], len(current.outputs))
+		for idx, name := range current.outputs {
+			sinks[idx] = obj.pipes[name]
+		}
+
+		group.Go(func() error {
+			defer obj.finishWriter(current.outputs)
+
+			return current.run(ctx, pipes, sinks, obj.gate)
+		})
 	}
 
-	obj.mutex.RUnlock()
+	obj.mutex.Unlock()
+
+	close(obj.started)
 
 	err := group.Wait()
 	if err != nil {
@@ -64,23 +234,47 @@ func (obj *Conveyer[T]) Run(ctx context.Context) error {
 	return nil
 }
 
-func (obj *Conveyer[T]) Send(inChName string, data T) error {
-	obj.mutex.RLock()
-	channel, exists := obj.pipes[inChName]
-	obj.mutex.RUnlock()
+// Stop cancels the running nodes. It only takes the control lock, so it never
+// waits behind a sender blocked on a full channel.
+func (obj *Conveyer[T]) Stop() {
+	obj.control.Lock()
+	defer obj.control.Unlock()
 
-	if !exists {
-		return ErrChannelNotFound
+	obj.stopRequested = true
+	if obj.cancel != nil {
+		obj.cancel()
 	}
+}
 
-	channel <- data
+// SendContext waits for room in the channel without holding the conveyer
+// lock, so a full channel never blocks inspection or control calls.
+func (obj *Conveyer[T]) SendContext(ctx context.Context, inChName string, data T) error {
+	current, err := obj.acquire(inChName)
+	if err != nil {
+		return err
+	}
+	defer current.senders.Done()
+
+	current.traffic.RLock()
+	defer current.traffic.RUnlock()
+
+	select {
+	case current.channel <- data:
+		return nil
+	case <-obj.stopped:
+		return ErrConveyerClosed
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send to %q: %w", inChName, ctx.Err())
+	}
+}
 
-	return nil
+func (obj *Conveyer[T]) Send(inChName string, data T) error {
+	return obj.SendContext(context.Background(), inChName, data)
 }
 
 func (obj *Conveyer[T]) Recv(outChName string) (T, error) {
 	obj.mutex.RLock()
-	channel, exists := obj.pipes[outChName]
+	current, exists := obj.pipes[outChName]
 	obj.mutex.RUnlock()
 
 	if !exists {
@@ -89,7 +283,10 @@ func (obj *Conveyer[T]) Recv(outChName string) (T, error) {
 		return res, ErrChannelNotFound
 	}
 
-	res, ok := <-channel
+	current.traffic.RLock()
+	res, ok := <-current.channel
+	current.traffic.RUnlock()
+
 	if !ok {
 		return res, ErrClosedChanelEmpty
 	}
@@ -97,53 +294,62 @@ func (obj *Conveyer[T]) Recv(outChName string) (T, error) {
 	return res, nil
 }
 
-func (obj *Conveyer[T]) RegisterDecorator(
-	functor func(c context.Context, input chan T, output chan T) error,
-	input string, output string,
+func (obj *Conveyer[T]) addNode(
+	kind string, inputs []string, outputs []string,
+	functor func(c context.Context, inputs []chan T, outputs []chan T) error,
 ) {
 	obj.mutex.Lock()
 	defer obj.mutex.Unlock()
 
-	in := obj.reserveChannel(input)
-	out := obj.reserveChannel(output)
-	obj.nodes = append(obj.nodes, func(c context.Context) error {
-		return functor(c, in, out)
+	for _, name := range inputs {
+		obj.reserveChannel(name)
+	}
+
+	for _, name := range outputs {
+		obj.reserveChannel(name)
+		obj.pipes[name].writers++
+	}
+
+	obj.nodes = append(obj.nodes, &node[T]{
+		name:    fmt.Sprintf("%s-%d", kind, len(obj.nodes)),
+		kind:    kind,
+		inputs:  inputs,
+		outputs: outputs,
+		functor: functor,
+		gate:    newGate(),
+		mutex:   sync.Mutex{},
+		state:   NodeIdle,
+		err:     nil,
+		held:    nil,
 	})
 }
 
+func (obj *Conveyer[T]) RegisterDecorator(
+	functor func(c context.Context, input chan T, output chan T) error,
+	input string, output string,
+) {
+	obj.addNode(kindDecorator, []string{input}, []string{output},
+		func(c context.Context, inputs []chan T, outputs []chan T) error {
+			return functor(c, inputs[0], outputs[0])
+		})
+}
+
 func (obj *Conveyer[T]) RegisterMultiplexer(
 	functor func(c context.Context, input []chan T, output chan T) error,
 	input []string, output string,
 ) {
-	obj.mutex.Lock()
-	defer obj.mutex.Unlock()
-
-	inputs := make([]chan T, len(input))
-	for idx, name := range input {
-		inputs[idx] = obj.reserveChannel(name)
-	}
-
-	out := obj.reserveChannel(output)
-	obj.nodes = append(obj.nodes, func(c context.Context) error {
-		return functor(c, inputs, out)
-	})
+	obj.addNode(kindMultiplexer, input, []string{output},
+		func(c context.Context, inputs []chan T, outputs []chan T) error {
+			return functor(c, inputs, outputs[0])
+		})
 }
 
 func (obj *Conveyer[T]) RegisterSeparator(
 	functor func(c context.Context, input chan T, output []chan T) error,
 	input string, output []string,
 ) {
-	obj.mutex.Lock()
-	defer obj.mutex.Unlock()
-
-	inCh := obj.reserveChannel(input)
-
-	outputs := make([]chan T, len(output))
-	for idx, name := range output {
-		outputs[idx] = obj.reserveChannel(name)
-	}
-
-	obj.nodes = append(obj.nodes, func(c context.Context) error {
-		return functor(c, inCh, outputs)
-	})
+	obj.addNode(kindSeparator, []string{input}, output,
+		func(c context.Context, inputs []chan T, outputs []chan T) error {
+			return functor(c, inputs[0], outputs)
+		})
 }
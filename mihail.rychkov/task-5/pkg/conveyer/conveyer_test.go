@@ -0,0 +1,196 @@
+package conveyer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Rychmick/task-5/pkg/conveyer"
+	"github.com/Rychmick/task-5/pkg/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recvWithin(t *testing.T, conv *conveyer.StringConveyer, name string, timeout time.Duration) (string, bool) {
+	t.Helper()
+
+	result := make(chan string, 1)
+
+	go func() {
+		res, err := conv.Recv(name)
+		if err == nil {
+			result <- res
+		}
+	}()
+
+	select {
+	case res := <-result:
+		return res, true
+	case <-time.After(timeout):
+		return "", false
+	}
+}
+
+func TestPauseStepResume(t *testing.T) {
+	t.Parallel()
+
+	conv := conveyer.New(5)
+	conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	conv.Pause()
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancelFunc()
+
+	done := make(chan error, 1)
+
+	go func() { done <- conv.Run(ctx) }()
+
+	for _, message := range []string{"1", "2", "3"} {
+		require.NoError(t, conv.Send("in", message))
+	}
+
+	require.Eventually(t, func() bool {
+		return conv.Nodes()[0].State == conveyer.NodePaused
+	}, time.Second, time.Millisecond*10)
+
+	peeked, err := conv.Peek("in", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, peeked)
+
+	require.NoError(t, conv.Step("decorator-0"))
+
+	res, ok := recvWithin(t, &conv, "out", time.Second)
+	require.True(t, ok)
+	assert.Equal(t, "decorated: 1", res)
+
+	peeked, err = conv.Peek("in", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, peeked)
+
+	conv.Resume()
+	require.ErrorIs(t, conv.Step("decorator-0"), conveyer.ErrNodeNotPaused)
+
+	_, err = conv.Peek("in", 0)
+	require.ErrorIs(t, err, conveyer.ErrConveyerNotPaused)
+
+	res, ok = recvWithin(t, &conv, "out", time.Second)
+	require.True(t, ok)
+	assert.Equal(t, "decorated: 2", res)
+
+	require.NoError(t, conv.PauseNode("decorator-0"))
+	require.ErrorIs(t, conv.Step("missing"), conveyer.ErrNodeNotFound)
+
+	conv.Stop()
+	require.NoError(t, <-done)
+}
+
+func TestFullChannelDoesNotBlockInspection(t *testing.T) {
+	t.Parallel()
+
+	conv := conveyer.New(1)
+	conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+	require.NoError(t, conv.Send("in", "1"))
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	blocked := make(chan error, 1)
+
+	go func() { blocked <- conv.SendContext(ctx, "in", "2") }()
+
+	registered := make(chan struct{})
+
+	go func() {
+		conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "out", "final")
+		close(registered)
+	}()
+
+	inspected := make(chan []conveyer.ChannelInfo, 1)
+
+	go func() { inspected <- conv.Channels() }()
+
+	select {
+	case <-registered:
+	case <-time.After(time.Second):
+		t.Fatal("registering a node waited for a blocked sender")
+	}
+
+	select {
+	case channels := <-inspected:
+		assert.Contains(t, channels, conveyer.ChannelInfo{Name: "in", Length: 1, Capacity: 1, Closed: false})
+	case <-time.After(time.Second):
+		t.Fatal("listing channels waited for a blocked sender")
+	}
+
+	conv.Pause()
+
+	require.Eventually(t, func() bool {
+		_, err := conv.Peek("in", 0)
+
+		return errors.Is(err, conveyer.ErrChannelBusy)
+	}, time.Second, time.Millisecond*10)
+
+	cancelFunc()
+	require.ErrorIs(t, <-blocked, context.Canceled)
+
+	peeked, err := conv.Peek("in", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, peeked)
+}
+
+func TestCloseInputWhileRelaySending(t *testing.T) {
+	t.Parallel()
+
+	for range 50 {
+		conv := conveyer.New(5)
+		conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		for _, message := range []string{"a", "no decorator", "b", "c", "d"} {
+			require.NoError(t, conv.Send("in", message))
+		}
+
+		done := make(chan error, 1)
+
+		go func() { done <- conv.Run(context.Background()) }()
+
+		<-conv.Started()
+		require.NoError(t, conv.CloseInput("in"))
+		require.ErrorIs(t, <-done, handlers.ErrNoDecorator)
+	}
+}
+
+func TestStoppedNodeReportsDroppedMessages(t *testing.T) {
+	t.Parallel()
+
+	for range 20 {
+		conv := conveyer.New(5)
+		conv.RegisterDecorator(handlers.PrefixDecoratorFunc, "in", "out")
+
+		for _, message := range []string{"a", "no decorator", "b", "c", "d"} {
+			require.NoError(t, conv.Send("in", message))
+		}
+
+		require.NoError(t, conv.CloseInput("in"))
+		require.ErrorIs(t, conv.Run(context.Background()), handlers.ErrNoDecorator)
+
+		res, err := conv.Recv("out")
+		require.NoError(t, err)
+		assert.Equal(t, "decorated: a", res)
+
+		left := 0
+
+		for {
+			_, err := conv.Conveyer.Recv("in")
+			if err != nil {
+				require.ErrorIs(t, err, conveyer.ErrClosedChanelEmpty)
+
+				break
+			}
+
+			left++
+		}
+
+		assert.Equal(t, 3, left+conv.Nodes()[0].Dropped, "every message after the failing one is left or dropped")
+	}
+}
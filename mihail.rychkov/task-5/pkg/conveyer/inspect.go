@@ -0,0 +1,172 @@
+package conveyer
+
+import (
+	"slices"
+	"strings"
+)
+
+type NodeInfo struct {
+	Name    string    `json:"name"`
+	Kind    string    `json:"kind"`
+	Inputs  []string  `json:"inputs"`
+	Outputs []string  `json:"outputs"`
+	State   NodeState `json:"state"`
+	Error   string    `json:"error,omitempty"`
+	Dropped int       `json:"dropped"`
+}
+
+type ChannelInfo struct {
+	Name     string `json:"name"`
+	Length   int    `json:"length"`
+	Capacity int    `json:"capacity"`
+	Closed   bool   `json:"closed"`
+}
+
+func (obj *Conveyer[T]) Nodes() []NodeInfo {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+
+	result := make([]NodeInfo, 0, len(obj.nodes))
+
+	for _, current := range obj.nodes {
+		state, err := current.currentState(obj.gate)
+
+		info := NodeInfo{
+			Name:    current.name,
+			Kind:    current.kind,
+			Inputs:  slices.Clone(current.inputs),
+			Outputs: slices.Clone(current.outputs),
+			State:   state,
+			Error:   "",
+			Dropped: current.dropped(),
+		}
+		if err != nil {
+			info.Error = err.Error()
+		}
+
+		result = append(result, info)
+	}
+
+	return result
+}
+
+func (obj *Conveyer[T]) Channels() []ChannelInfo {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+
+	result := make([]ChannelInfo, 0, len(obj.pipes))
+	for name, current := range obj.pipes {
+		result = append(result, ChannelInfo{name, len(current.channel), cap(current.channel), current.closed})
+	}
+
+	slices.SortFunc(result, func(lhs, rhs ChannelInfo) int {
+		return strings.Compare(lhs.Name, rhs.Name)
+	})
+
+	return result
+}
+
+// Peek takes the buffered messages out of the channel and puts them back in
+// the same order, so it mutates the channel. It therefore refuses unless the
+// whole conveyer is paused, and while a send or receive on the channel is
+// pending; nothing else can touch the channel until the messages are back.
+// Messages already held by a node are not seen.
+func (obj *Conveyer[T]) Peek(chName string, limit int) ([]T, error) {
+	if !obj.Paused() {
+		return nil, ErrConveyerNotPaused
+	}
+
+	current, err := obj.acquire(chName)
+	if err != nil {
+		return nil, err
+	}
+	defer current.senders.Done()
+
+	if !current.traffic.TryLock() {
+		return nil, ErrChannelBusy
+	}
+	defer current.traffic.Unlock()
+
+	channel := current.channel
+	buffered := make([]T, 0, len(channel))
+
+	for range len(channel) {
+		buffered = append(buffered, <-channel)
+	}
+
+	for _, data := range buffered {
+		channel <- data
+	}
+
+	if limit > 0 && len(buffered) > limit {
+		buffered = buffered[:limit]
+	}
+
+	return buffered, nil
+}
+
+func (obj *Conveyer[T]) findNode(name string) (*node[T], error) {
+	obj.mutex.RLock()
+	defer obj.mutex.RUnlock()
+
+	for _, current := range obj.nodes {
+		if current.name == name {
+			return current, nil
+		}
+	}
+
+	return nil, ErrNodeNotFound
+}
+
+func (obj *Conveyer[T]) PauseNode(name string) error {
+	found, err := obj.findNode(name)
+	if err != nil {
+		return err
+	}
+
+	found.gate.setPaused(true)
+
+	return nil
+}
+
+func (obj *Conveyer[T]) ResumeNode(name string) error {
+	found, err := obj.findNode(name)
+	if err != nil {
+		return err
+	}
+
+	found.gate.setPaused(false)
+
+	return nil
+}
+
+func (obj *Conveyer[T]) Pause() {
+	obj.gate.setPaused(true)
+}
+
+func (obj *Conveyer[T]) Resume() {
+	obj.gate.setPaused(false)
+}
+
+func (obj *Conveyer[T]) Paused() bool {
+	paused, _ := obj.gate.state()
+
+	return paused
+}
+
+// Step lets one more message into a node that is paused by itself or with the whole conveyer.
+func (obj *Conveyer[T]) Step(name string) error {
+	found, err := obj.findNode(name)
+	if err != nil {
+		return err
+	}
+
+	nodePaused, _ := found.gate.state()
+	if !nodePaused && !obj.Paused() {
+		return ErrNodeNotPaused
+	}
+
+	found.gate.addStep()
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package conveyer
+
+import (
+	"context"
+	"sync"
+)
+
+type NodeState string
+
+const (
+	NodeIdle     NodeState = "idle"
+	NodeRunning  NodeState = "running"
+	NodePaused   NodeState = "paused"
+	NodeFinished NodeState = "finished"
+	NodeFailed   NodeState = "failed"
+)
+
+const (
+	kindDecorator   = "decorator"
+	kindMultiplexer = "multiplexer"
+	kindSeparator   = "separator"
+)
+
+type gate struct {
+	mutex  sync.Mutex
+	paused bool
+	steps  int
+	wake   chan struct{}
+}
+
+func newGate() *gate {
+	return &gate{sync.Mutex{}, false, 0, make(chan struct{})}
+}
+
+func (obj *gate) state() (bool, <-chan struct{}) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	return obj.paused, obj.wake
+}
+
+func (obj *gate) notify() {
+	close(obj.wake)
+	obj.wake = make(chan struct{})
+}
+
+func (obj *gate) setPaused(paused bool) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.paused = paused
+	obj.steps = 0
+
+	obj.notify()
+}
+
+func (obj *gate) addStep() {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.steps++
+
+	obj.notify()
+}
+
+func (obj *gate) takeStep() bool {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	if obj.steps == 0 {
+		return false
+	}
+
+	obj.steps--
+
+	return true
+}
+
+func (obj *gate) returnStep() {
+	obj.addStep()
+}
+
+type node[T any] struct {
+	name    string
+	kind    string
+	inputs  []string
+	outputs []string
+	functor func(c context.Context, inputs []chan T, outputs []chan T) error
+	gate    *gate
+	mutex   sync.Mutex
+	state   NodeState
+	err     error
+	held    []T
+}
+
+// hold keeps a message a relay took from or for a pipe but could not hand on
+// before the node stopped. Relays never write back into the input pipe: it
+// may be closed by then.
+func (obj *node[T]) hold(data T) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.held = append(obj.held, data)
+}
+
+func (obj *node[T]) dropped() int {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	return len(obj.held)
+}
+
+func (obj *node[T]) setResult(err error) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.err = err
+	if err != nil {
+		obj.state = NodeFailed
+	} else {
+		obj.state = NodeFinished
+	}
+}
+
+func (obj *node[T]) setRunning() {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	obj.state = NodeRunning
+	obj.err = nil
+}
+
+func (obj *node[T]) currentState(global *gate) (NodeState, error) {
+	obj.mutex.Lock()
+	defer obj.mutex.Unlock()
+
+	if obj.state == NodeRunning {
+		nodePaused, _ := obj.gate.state()
+		globalPaused, _ := global.state()
+
+		if nodePaused || globalPaused {
+			return NodePaused, nil
+		}
+	}
+
+	return obj.state, obj.err
+}
+
+// relay forwards messages from a named pipe to the node while neither the node
+// nor the whole conveyer is paused; a paused node only lets stepped messages through.
+// A message still in flight when ctx is done goes to hold.
+func relay[T any](ctx context.Context, control *gate, global *gate, src *pipe[T], dst chan T, hold func(T)) {
+	for {
+		nodePaused, nodeWake := control.state()
+		globalPaused, globalWake := global.state()
+
+		stepping := nodePaused || globalPaused
+		if stepping && !control.takeStep() {
+			select {
+			case <-nodeWake:
+			case <-globalWake:
+			case <-ctx.Done():
+				return
+			}
+
+			continue
+		}
+
+		src.traffic.RLock()
+
+		select {
+		case data, ok := <-src.channel:
+			src.traffic.RUnlock()
+
+			if !ok {
+				close(dst)
+
+				return
+			}
+
+			select {
+			case dst <- data:
+			case <-ctx.Done():
+				hold(data)
+
+				return
+			}
+
+			continue
+		case <-nodeWake:
+		case <-globalWake:
+		case <-ctx.Done():
+			src.traffic.RUnlock()
+
+			return
+		}
+
+		src.traffic.RUnlock()
+
+		if stepping {
+			control.returnStep()
+		}
+	}
+}
+
+// forward passes what the node writes on to a named pipe until the node is done
+// writing. Once ctx is done the rest goes to hold, so the node never blocks on it.
+func forward[T any](ctx context.Context, src chan T, dst *pipe[T], hold func(T)) {
+	for data := range src {
+		if ctx.Err() != nil {
+			hold(data)
+
+			continue
+		}
+
+		dst.traffic.RLock()
+
+		select {
+		case dst.channel <- data:
+		case <-ctx.Done():
+			hold(data)
+		}
+
+		dst.traffic.RUnlock()
+	}
+}
+
+func (obj *node[T]) run(ctx context.Context, pipes []*pipe[T], sinks []*pipe[T], global *gate) error {
+	obj.setRunning()
+
+	relayCtx, cancel := context.WithCancel(ctx)
+
+	var readers, writers sync.WaitGroup
+
+	inputs := make([]chan T, len(pipes))
+	for idx, pipe := range pipes {
+		inputs[idx] = make(chan T)
+
+		readers.Add(1)
+
+		go func() {
+			defer readers.Done()
+
+			relay(relayCtx, obj.gate, global, pipe, inputs[idx], obj.hold)
+		}()
+	}
+
+	outputs := make([]chan T, len(sinks))
+	for idx, sink := range sinks {
+		outputs[idx] = make(chan T)
+
+		writers.Add(1)
+
+		go func() {
+			defer writers.Done()
+
+			forward(ctx, outputs[idx], sink, obj.hold)
+		}()
+	}
+
+	err := obj.functor(ctx, inputs, outputs)
+
+	cancel()
+	readers.Wait()
+
+	for _, output := range outputs {
+		close(output)
+	}
+
+	writers.Wait()
+
+	obj.setResult(err)
+
+	return err
+}
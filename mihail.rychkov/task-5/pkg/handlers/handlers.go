@@ -12,37 +12,14 @@ var (
 	ErrEmptyChannelList = errors.New("channels slice is empty")
 )
 
-func PrefixDecoratorFunc(ctx context.Context, input chan string, output chan string) error {
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		default:
-		}
-
-		select {
-		case str, ok := <-input:
-			if !ok {
-				return nil
-			}
-
-			if strings.Contains(str, "no decorator") {
-				return ErrNoDecorator
-			}
-
-			if !strings.HasPrefix(str, "decorated: ") {
-				str = "decorated: " + str
-			}
+const (
+	defaultPrefix       = "decorated: "
+	noDecoratorMarker   = "no decorator"
+	noMultiplexerMarker = "no multiplexer"
+)
 
-			select {
-			case output <- str:
-			case <-ctx.Done():
-				return nil
-			}
-		case <-ctx.Done():
-			return nil
-		}
-	}
+func PrefixDecoratorFunc(ctx context.Context, input chan string, output chan string) error {
+	return NewPrefixDecorator(defaultPrefix, []string{noDecoratorMarker}, ErrNoDecorator)(ctx, input, output)
 }
 
 func SeparatorFunc(ctx context.Context, input chan string, outputs []chan string) error {
@@ -104,7 +81,7 @@ func MultiplexerFunc(ctx context.Context, inputs []chan string, output chan stri
 						return
 					}
 
-					if !strings.Contains(str, "no multiplexer") {
+					if !strings.Contains(str, noMultiplexerMarker) {
 						select {
 						case output <- str:
 						case <-ctx.Done():
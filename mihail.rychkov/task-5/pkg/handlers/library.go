@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+type DecoratorFunc func(ctx context.Context, input chan string, output chan string) error
+
+var (
+	ErrRejected    = errors.New("message rejected")
+	ErrInvalidJSON = errors.New("message is not a json object")
+	ErrBadArgument = errors.New("invalid handler argument")
+)
+
+func transform(
+	ctx context.Context, input chan string, output chan string,
+	process func(str string) ([]string, error),
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		select {
+		case str, ok := <-input:
+			if !ok {
+				return nil
+			}
+
+			results, err := process(str)
+			if err != nil {
+				return err
+			}
+
+			for _, result := range results {
+				select {
+				case output <- result:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func NewPrefixDecorator(prefix string, reject []string, rejectErr error) DecoratorFunc {
+	if rejectErr == nil {
+		rejectErr = ErrRejected
+	}
+
+	return func(ctx context.Context, input chan string, output chan string) error {
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			for _, marker := range reject {
+				if strings.Contains(str, marker) {
+					return nil, rejectErr
+				}
+			}
+
+			if !strings.HasPrefix(str, prefix) {
+				str = prefix + str
+			}
+
+			return []string{str}, nil
+		})
+	}
+}
+
+func Map(mapper func(str string) string) DecoratorFunc {
+	return func(ctx context.Context, input chan string, output chan string) error {
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			return []string{mapper(str)}, nil
+		})
+	}
+}
+
+func Filter(keep func(str string) bool) DecoratorFunc {
+	return func(ctx context.Context, input chan string, output chan string) error {
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			if !keep(str) {
+				return nil, nil
+			}
+
+			return []string{str}, nil
+		})
+	}
+}
+
+func FlatMap(mapper func(str string) []string) DecoratorFunc {
+	return func(ctx context.Context, input chan string, output chan string) error {
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			return mapper(str), nil
+		})
+	}
+}
+
+func RegexReplace(pattern *regexp.Regexp, replacement string) DecoratorFunc {
+	return Map(func(str string) string {
+		return pattern.ReplaceAllString(str, replacement)
+	})
+}
+
+func Normalize(toLower bool) DecoratorFunc {
+	return Map(func(str string) string {
+		str = strings.Join(strings.Fields(str), " ")
+		if toLower {
+			str = strings.ToLower(str)
+		}
+
+		return str
+	})
+}
+
+func Trim(cutset string) DecoratorFunc {
+	if cutset == "" {
+		return Map(strings.TrimSpace)
+	}
+
+	return Map(func(str string) string {
+		return strings.Trim(str, cutset)
+	})
+}
+
+func JSONField(path string) DecoratorFunc {
+	keys := strings.Split(path, ".")
+
+	return func(ctx context.Context, input chan string, output chan string) error {
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			var current any
+
+			err := json.Unmarshal([]byte(str), &current)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+			}
+
+			for _, key := range keys {
+				object, isObject := current.(map[string]any)
+				if !isObject {
+					return nil, nil
+				}
+
+				current, isObject = object[key]
+				if !isObject {
+					return nil, nil
+				}
+			}
+
+			if text, isString := current.(string); isString {
+				return []string{text}, nil
+			}
+
+			raw, err := json.Marshal(current)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize field %q: %w", path, err)
+			}
+
+			return []string{string(raw)}, nil
+		})
+	}
+}
+
+// Sample passes every n-th message; a rate below one fails the handler with ErrBadArgument when it runs.
+func Sample(every int) DecoratorFunc {
+	return func(ctx context.Context, input chan string, output chan string) error {
+		if every <= 0 {
+			return fmt.Errorf("%w: sample rate must be positive, got %d", ErrBadArgument, every)
+		}
+
+		var counter int
+
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			counter++
+			if counter < every {
+				return nil, nil
+			}
+
+			counter = 0
+
+			return []string{str}, nil
+		})
+	}
+}
+
+// Dedupe drops messages seen among the last window distinct ones. A window of
+// zero or less never forgets, so memory grows with the number of distinct messages.
+func Dedupe(window int) DecoratorFunc {
+	return func(ctx context.Context, input chan string, output chan string) error {
+		seen := make(map[string]struct{})
+		order := make([]string, 0, max(window, 0))
+
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			if _, exists := seen[str]; exists {
+				return nil, nil
+			}
+
+			seen[str] = struct{}{}
+
+			if window > 0 {
+				order = append(order, str)
+				if len(order) > window {
+					delete(seen, order[0])
+					order = order[1:]
+				}
+			}
+
+			return []string{str}, nil
+		})
+	}
+}
+
+func Tee(writer io.Writer) DecoratorFunc {
+	return func(ctx context.Context, input chan string, output chan string) error {
+		return transform(ctx, input, output, func(str string) ([]string, error) {
+			_, err := fmt.Fprintln(writer, str)
+			if err != nil {
+				return nil, fmt.Errorf("failed to write message: %w", err)
+			}
+
+			return []string{str}, nil
+		})
+	}
+}
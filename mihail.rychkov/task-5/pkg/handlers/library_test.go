@@ -0,0 +1,159 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rychmick/task-5/pkg/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errCustomReject = errors.New("custom reject")
+
+func runDecorator(t *testing.T, decorator handlers.DecoratorFunc, messages ...string) ([]string, error) {
+	t.Helper()
+
+	input := make(chan string, len(messages))
+	output := make(chan string, len(messages)*4)
+
+	for _, message := range messages {
+		input <- message
+	}
+
+	close(input)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second)
+	defer cancelFunc()
+
+	err := decorator(ctx, input, output)
+
+	close(output)
+
+	results := make([]string, 0, len(output))
+	for message := range output {
+		results = append(results, message)
+	}
+
+	return results, err
+}
+
+func TestPrefixDecorator(t *testing.T) {
+	t.Parallel()
+
+	decorator := handlers.NewPrefixDecorator("> ", []string{"drop me"}, errCustomReject)
+
+	res, err := runDecorator(t, decorator, "a", "> b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"> a", "> b"}, res)
+
+	res, err = runDecorator(t, decorator, "a", "please drop me", "c")
+	require.ErrorIs(t, err, errCustomReject)
+	assert.Equal(t, []string{"> a"}, res)
+
+	_, err = runDecorator(t, handlers.NewPrefixDecorator("", []string{"x"}, nil), "x")
+	require.ErrorIs(t, err, handlers.ErrRejected)
+}
+
+func TestMapFilterFlatMap(t *testing.T) {
+	t.Parallel()
+
+	res, err := runDecorator(t, handlers.Map(strings.ToUpper), "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, res)
+
+	res, err = runDecorator(t, handlers.Filter(func(str string) bool { return str != "b" }), "a", "b", "c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, res)
+
+	res, err = runDecorator(t, handlers.FlatMap(strings.Fields), "a b", "", "c")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, res)
+}
+
+func TestTextHandlers(t *testing.T) {
+	t.Parallel()
+
+	res, err := runDecorator(t, handlers.RegexReplace(regexp.MustCompile(`\d+`), "#"), "a1b22")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a#b#"}, res)
+
+	res, err = runDecorator(t, handlers.Normalize(true), "  Hello \t  World ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello world"}, res)
+
+	res, err = runDecorator(t, handlers.Trim(""), "  x  ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x"}, res)
+
+	res, err = runDecorator(t, handlers.Trim("-"), "--x--")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x"}, res)
+}
+
+func TestJSONField(t *testing.T) {
+	t.Parallel()
+
+	res, err := runDecorator(t, handlers.JSONField("user.name"),
+		`{"user":{"name":"bob"}}`, `{"user":{}}`, `{"user":{"name":{"first":"al"}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", `{"first":"al"}`}, res)
+
+	_, err = runDecorator(t, handlers.JSONField("user"), "not json")
+	require.ErrorIs(t, err, handlers.ErrInvalidJSON)
+}
+
+func TestSampleDedupeTee(t *testing.T) {
+	t.Parallel()
+
+	res, err := runDecorator(t, handlers.Sample(2), "1", "2", "3", "4", "5")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "4"}, res)
+
+	_, err = runDecorator(t, handlers.Sample(0), "1")
+	require.ErrorIs(t, err, handlers.ErrBadArgument)
+
+	res, err = runDecorator(t, handlers.Dedupe(0), "a", "b", "a", "c", "b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, res)
+
+	res, err = runDecorator(t, handlers.Dedupe(1), "a", "b", "a", "a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "a"}, res)
+
+	var buffer bytes.Buffer
+
+	res, err = runDecorator(t, handlers.Tee(&buffer), "x", "y")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, res)
+	assert.Equal(t, "x\ny\n", buffer.String())
+}
+
+func TestLibraryCancellation(t *testing.T) {
+	t.Parallel()
+
+	input := make(chan string, 1)
+	output := make(chan string)
+
+	input <- "blocked"
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+
+	go func() { done <- handlers.Map(strings.ToUpper)(ctx, input, output) }()
+
+	cancelFunc()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("handler did not stop after cancellation")
+	}
+}
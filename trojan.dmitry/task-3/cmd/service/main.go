@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 
@@ -17,7 +18,7 @@ func main() {
 		panic(fmt.Errorf("load config: %w", err))
 	}
 
-	err = currency.Process(config.InputFile, config.OutputFile)
+	err = currency.Process(context.Background(), config.InputFile, config.OutputFile)
 	if err != nil {
 		panic(fmt.Errorf("process currency: %w", err))
 	}
@@ -1,54 +1,32 @@
 package currency
 
 import (
+	"bytes"
+	"context"
 	"fmt"
-	"sort"
+	"os"
 
-	"github.com/DimasFantomasA/task-3/internal/cbrusxml"
 	"github.com/DimasFantomasA/task-3/internal/jsonfile"
-	"github.com/DimasFantomasA/task-3/internal/xmlparser"
+	"github.com/DimasFantomasA/task-3/pkg/converter"
 )
 
 const defaultFilePerm = 0o755
 
-func Process(inputPath, outputPath string) error {
-	valCurs, err := LoadValutes(inputPath)
+func Process(ctx context.Context, inputPath, outputPath string) error {
+	input, err := os.Open(inputPath)
 	if err != nil {
-		return err
+		return fmt.Errorf("open input: %w", err)
 	}
+	defer input.Close()
 
-	valutes := PrepareValutes(valCurs)
-	SortValutes(valutes)
+	var output bytes.Buffer
 
-	err = StoreValutes(outputPath, valutes)
+	err = converter.Convert(ctx, input, &output, converter.Options{})
 	if err != nil {
-		return err
+		return fmt.Errorf("convert %s: %w", inputPath, err)
 	}
 
-	return nil
-}
-
-func LoadValutes(path string) (*cbrusxml.ValCurs, error) {
-	valCurs, err := xmlparser.ParseXMLFile[cbrusxml.ValCurs](path)
-	if err != nil {
-		return nil, fmt.Errorf("parse xml: %w", err)
-	}
-
-	return valCurs, nil
-}
-
-func PrepareValutes(valCurs *cbrusxml.ValCurs) []cbrusxml.Valute {
-	return append([]cbrusxml.Valute{}, valCurs.Valutes...)
-}
-
-func SortValutes(valutes []cbrusxml.Valute) {
-	sort.Slice(valutes, func(i, j int) bool {
-		return valutes[i].Value > valutes[j].Value
-	})
-}
-
-func StoreValutes(path string, valutes []cbrusxml.Valute) error {
-	err := jsonfile.Save(path, valutes, defaultFilePerm)
+	err = jsonfile.Write(outputPath, output.Bytes(), defaultFilePerm)
 	if err != nil {
 		return fmt.Errorf("save json: %w", err)
 	}
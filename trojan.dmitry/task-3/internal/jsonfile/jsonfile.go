@@ -1,24 +1,18 @@
 package jsonfile
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 )
 
-func Save(path string, data any, perm fs.FileMode) error {
+func Write(path string, content []byte, perm fs.FileMode) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, perm); err != nil {
 		return fmt.Errorf("mkdir %s: %w", dir, err)
 	}
 
-	content, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal json: %w", err)
-	}
-
 	tmpFile, err := os.CreateTemp(dir, "tmp-*.json")
 	if err != nil {
 		return fmt.Errorf("create temp file: %w", err)
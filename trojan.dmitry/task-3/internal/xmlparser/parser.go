@@ -1,36 +1,29 @@
 package xmlparser
 
 import (
-	"bytes"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
-	"os"
 
 	"golang.org/x/net/html/charset"
 )
 
-func ParseXMLFile[T any](path string) (*T, error) {
+func ParseXML[T any](reader io.Reader) (*T, error) {
 	var dest T
-	if err := ParseXMLFileInto(path, &dest); err != nil {
+	if err := ParseXMLInto(reader, &dest); err != nil {
 		return nil, err
 	}
 
 	return &dest, nil
 }
 
-func ParseXMLFileInto(path string, val any) error {
-	raw, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("read file %s: %w", path, err)
-	}
-
-	dec := xml.NewDecoder(bytes.NewReader(raw))
+func ParseXMLInto(reader io.Reader, val any) error {
+	dec := xml.NewDecoder(reader)
 
 	dec.CharsetReader = charset.NewReaderLabel
 
-	err = dec.Decode(val)
+	err := dec.Decode(val)
 	if err != nil && !errors.Is(err, io.EOF) {
 		return fmt.Errorf("decode xml: %w", err)
 	}
@@ -0,0 +1,156 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/DimasFantomasA/task-3/internal/cbrusxml"
+	"github.com/DimasFantomasA/task-3/internal/xmlparser"
+)
+
+var (
+	ErrRead     = errors.New("read input")
+	ErrDecode   = errors.New("decode xml")
+	ErrValidate = errors.New("validate valutes")
+	ErrEncode   = errors.New("encode json")
+
+	ErrMissingCharCode = errors.New("char code is empty")
+	ErrNegativeValue   = errors.New("value is negative")
+)
+
+// Error tells which stage of Convert failed: Stage is one of ErrRead,
+// ErrDecode, ErrValidate and ErrEncode, so errors.Is works with both.
+// Cancellation is not a stage failure: Convert returns ctx.Err() as is.
+type Error struct {
+	Stage error
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %v", e.Stage, e.Err)
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Stage, e.Err}
+}
+
+// Options zero value matches the service output: highest value first, indented
+// json and no checks beyond parsing. Validate rejects valutes without a char
+// code or with a negative value.
+type Options struct {
+	Ascending bool
+	Compact   bool
+	Validate  bool
+}
+
+type contextReader struct {
+	ctx    context.Context //nolint:containedctx
+	reader io.Reader
+}
+
+func (r contextReader) Read(buf []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err //nolint:wrapcheck
+	}
+
+	return r.reader.Read(buf) //nolint:wrapcheck
+}
+
+// Convert reads a CBR ValCurs xml document in any charset and writes its
+// valutes as a json array sorted by value, the highest first by default.
+// Nothing is written unless every earlier stage succeeded.
+func Convert(ctx context.Context, input io.Reader, output io.Writer, opts Options) error {
+	raw, err := io.ReadAll(contextReader{ctx, input})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr //nolint:wrapcheck
+	}
+
+	if err != nil {
+		return &Error{ErrRead, err}
+	}
+
+	valCurs, err := xmlparser.ParseXML[cbrusxml.ValCurs](bytes.NewReader(raw))
+	if err != nil {
+		return &Error{ErrDecode, err}
+	}
+
+	valutes := prepareValutes(valCurs)
+
+	if opts.Validate {
+		err = validateValutes(valutes)
+		if err != nil {
+			return &Error{ErrValidate, err}
+		}
+	}
+
+	sortValutes(valutes, opts.Ascending)
+
+	err = ctx.Err()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	err = encode(output, valutes, opts.Compact)
+	if err != nil {
+		return &Error{ErrEncode, err}
+	}
+
+	return nil
+}
+
+func prepareValutes(valCurs *cbrusxml.ValCurs) []cbrusxml.Valute {
+	return append([]cbrusxml.Valute{}, valCurs.Valutes...)
+}
+
+func validateValutes(valutes []cbrusxml.Valute) error {
+	for i, valute := range valutes {
+		if valute.CharCode == "" {
+			return fmt.Errorf("valute %d: %w", i, ErrMissingCharCode)
+		}
+
+		if valute.Value < 0 {
+			return fmt.Errorf("valute %s: %w", valute.CharCode, ErrNegativeValue)
+		}
+	}
+
+	return nil
+}
+
+func sortValutes(valutes []cbrusxml.Valute, ascending bool) {
+	sort.SliceStable(valutes, func(i, j int) bool {
+		if ascending {
+			return valutes[i].Value < valutes[j].Value
+		}
+
+		return valutes[i].Value > valutes[j].Value
+	})
+}
+
+func encode(output io.Writer, valutes []cbrusxml.Valute, compact bool) error {
+	var (
+		content []byte
+		err     error
+	)
+
+	if compact {
+		content, err = json.Marshal(valutes)
+	} else {
+		content, err = json.MarshalIndent(valutes, "", "  ")
+	}
+
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	_, err = output.Write(content)
+	if err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+
+	return nil
+}
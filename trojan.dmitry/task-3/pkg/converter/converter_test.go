@@ -0,0 +1,203 @@
+package converter_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/DimasFantomasA/task-3/pkg/converter"
+)
+
+const (
+	document = `<?xml version="1.0" encoding="UTF-8"?>
+<ValCurs Date="02.03.2024" name="Foreign Currency Market">
+	<Valute ID="R01235"><NumCode>840</NumCode><CharCode>USD</CharCode><Value>91,2</Value></Valute>
+	<Valute ID="R01239"><NumCode>978</NumCode><CharCode>EUR</CharCode><Value>98,7</Value></Valute>
+	<Valute ID="R01375"><NumCode>156</NumCode><CharCode>CNY</CharCode><Value>12,5</Value></Valute>
+</ValCurs>`
+
+	// "Доллар США" in windows-1251
+	windows1251 = "<?xml version=\"1.0\" encoding=\"windows-1251\"?>\n<ValCurs>" +
+		"<Valute><NumCode>840</NumCode><CharCode>USD</CharCode>" +
+		"<Name>\xc4\xee\xeb\xeb\xe0\xf0 \xd1\xd8\xc0</Name><Value>91,2</Value></Valute></ValCurs>"
+
+	unchecked = `<ValCurs>
+	<Valute><NumCode>840</NumCode><CharCode></CharCode><Value>91,2</Value></Valute>
+</ValCurs>`
+
+	negative = `<ValCurs>
+	<Valute><NumCode>840</NumCode><CharCode>USD</CharCode><Value>-1</Value></Valute>
+</ValCurs>`
+)
+
+var errWrite = errors.New("disk full")
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  io.Reader
+		output io.Writer
+		opts   converter.Options
+		want   string
+		stage  error
+		err    error
+	}{
+		{
+			name:  "descending indented",
+			input: strings.NewReader(document),
+			want: `[
+  {
+    "num_code": 978,
+    "char_code": "EUR",
+    "value": 98.7
+  },
+  {
+    "num_code": 840,
+    "char_code": "USD",
+    "value": 91.2
+  },
+  {
+    "num_code": 156,
+    "char_code": "CNY",
+    "value": 12.5
+  }
+]`,
+		},
+		{
+			name:  "ascending compact",
+			input: strings.NewReader(document),
+			opts:  converter.Options{Ascending: true, Compact: true, Validate: true},
+			want: `[{"num_code":156,"char_code":"CNY","value":12.5},` +
+				`{"num_code":840,"char_code":"USD","value":91.2},` +
+				`{"num_code":978,"char_code":"EUR","value":98.7}]`,
+		},
+		{
+			name:  "windows-1251",
+			input: strings.NewReader(windows1251),
+			opts:  converter.Options{Compact: true},
+			want:  `[{"num_code":840,"char_code":"USD","value":91.2}]`,
+		},
+		{
+			name:  "not validated by default",
+			input: strings.NewReader(unchecked),
+			opts:  converter.Options{Compact: true},
+			want:  `[{"num_code":840,"char_code":"","value":91.2}]`,
+		},
+		{
+			name:  "read error",
+			input: iotest.ErrReader(io.ErrUnexpectedEOF),
+			stage: converter.ErrRead,
+			err:   io.ErrUnexpectedEOF,
+		},
+		{
+			name:  "malformed xml",
+			input: strings.NewReader("<ValCurs><Valute>"),
+			stage: converter.ErrDecode,
+		},
+		{
+			name:  "bad value",
+			input: strings.NewReader(strings.Replace(document, "91,2", "n/a", 1)),
+			stage: converter.ErrDecode,
+		},
+		{
+			name:  "missing char code",
+			input: strings.NewReader(unchecked),
+			opts:  converter.Options{Validate: true},
+			stage: converter.ErrValidate,
+			err:   converter.ErrMissingCharCode,
+		},
+		{
+			name:  "negative value",
+			input: strings.NewReader(negative),
+			opts:  converter.Options{Validate: true},
+			stage: converter.ErrValidate,
+			err:   converter.ErrNegativeValue,
+		},
+		{
+			name:   "write error",
+			input:  strings.NewReader(document),
+			output: failingWriter{},
+			stage:  converter.ErrEncode,
+			err:    errWrite,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buffer bytes.Buffer
+
+			output := test.output
+			if output == nil {
+				output = &buffer
+			}
+
+			err := converter.Convert(context.Background(), test.input, output, test.opts)
+
+			if test.stage == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if buffer.String() != test.want {
+					t.Fatalf("got %s, want %s", buffer.String(), test.want)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, test.stage) {
+				t.Fatalf("got %v, want stage %v", err, test.stage)
+			}
+
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Fatalf("got %v, want %v", err, test.err)
+			}
+
+			var stageErr *converter.Error
+			if !errors.As(err, &stageErr) || stageErr.Stage != test.stage {
+				t.Fatalf("got %#v, want a *converter.Error for %v", err, test.stage)
+			}
+
+			if buffer.Len() != 0 {
+				t.Fatalf("wrote %q despite the error", buffer.String())
+			}
+		})
+	}
+}
+
+func TestConvertCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buffer bytes.Buffer
+
+	err := converter.Convert(ctx, strings.NewReader(document), &buffer, converter.Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+
+	var stageErr *converter.Error
+	if errors.As(err, &stageErr) {
+		t.Fatalf("cancellation reported as the %v stage", stageErr.Stage)
+	}
+
+	if buffer.Len() != 0 {
+		t.Fatalf("wrote %q after cancellation", buffer.String())
+	}
+}